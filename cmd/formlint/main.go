@@ -0,0 +1,103 @@
+// Command formlint checks form bundles (forms.json) for mistakes before they
+// reach devices.
+//
+//	formlint internal/forms/assets/forms.json
+//
+// Each problem is printed with its JSON path. The exit status is 1 if any
+// bundle has errors, 2 on usage errors; warnings alone don't fail.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"forms-app/internal/forms"
+)
+
+func main() {
+	strict := flag.Bool("strict", false, "treat warnings as errors")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: formlint [-strict] forms.json...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		issues, err := lintFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Printf("%s: %s\n", path, issue)
+			if !issue.Warning || *strict {
+				failed = true
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func lintFile(path string) ([]forms.LintIssue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// schema mismatches are reported alongside the lint issues
+	var issues []forms.LintIssue
+	var schemaErr *forms.SchemaError
+	if err := forms.ValidateBundleJSON(data); errors.As(err, &schemaErr) {
+		issues = schemaErr.Issues
+	}
+
+	var bundle forms.FormBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		if len(issues) > 0 {
+			return issues, nil // they already explain the type mismatch
+		}
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			line, col := position(data, syntaxErr.Offset)
+			return nil, fmt.Errorf("line %d, column %d: %v", line, col, err)
+		case errors.As(err, &typeErr):
+			line, col := position(data, typeErr.Offset)
+			return nil, fmt.Errorf("line %d, column %d: $.%s: %v", line, col, typeErr.Field, err)
+		}
+		return nil, err
+	}
+	reported := make(map[string]bool)
+	for _, issue := range issues {
+		reported[issue.Path] = true
+	}
+	for _, issue := range forms.LintBundle(bundle) {
+		if !reported[issue.Path] {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// position converts a byte offset into a 1-based line and column.
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
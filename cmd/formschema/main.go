@@ -0,0 +1,49 @@
+// Command formschema writes the JSON Schema for forms.json, generated from
+// the forms package types. It is run by "go generate ./internal/forms".
+//
+//	formschema -o internal/forms/assets/forms.schema.json
+//	formschema -check internal/forms/assets/forms.schema.json
+//
+// With -check it writes nothing and exits 1 if the file is out of date.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"forms-app/internal/forms"
+)
+
+func main() {
+	out := flag.String("o", "", "write the schema to this file instead of stdout")
+	check := flag.String("check", "", "exit 1 if this schema file is out of date")
+	flag.Parse()
+
+	schema, err := forms.BundleSchemaJSON()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "formschema:", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case *check != "":
+		current, err := os.ReadFile(*check)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "formschema:", err)
+			os.Exit(1)
+		}
+		if !bytes.Equal(current, schema) {
+			fmt.Fprintf(os.Stderr, "formschema: %s is out of date; run go generate ./internal/forms\n", *check)
+			os.Exit(1)
+		}
+	case *out != "":
+		if err := os.WriteFile(*out, schema, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "formschema:", err)
+			os.Exit(1)
+		}
+	default:
+		os.Stdout.Write(schema)
+	}
+}
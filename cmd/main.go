@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
@@ -74,6 +75,9 @@ func statusBanner(source string) fyne.CanvasObject {
 }
 
 func main() {
+	fakeLocation := flag.Bool("fake-location", false, "fill geopoint fields with a fixed test location (development only)")
+	flag.Parse()
+
 	a := app.NewWithID("com.example.formsapp")
 	w := a.NewWindow("SukumaPro")
 	ui.ApplyCustomTheme(a)
@@ -81,6 +85,17 @@ func main() {
 	apiURL := "https://example.com/api/forms"
 	appName := "forms-app"
 
+	// No GPS on desktop: geopoint fields report the location as unavailable,
+	// unless a fixed test location is asked for with -fake-location
+	if !fyne.CurrentDevice().IsMobile() {
+		forms.DefaultLocationProvider = forms.NoLocationProvider{}
+		if *fakeLocation {
+			forms.DefaultLocationProvider = forms.FakeLocationProvider{
+				Point: forms.GeoPoint{Latitude: 0.3476, Longitude: 32.5825, Accuracy: 10},
+			}
+		}
+	}
+
 	allForms, order, source, err := forms.LoadForms(a, apiURL, appName)
 	fmt.Println("ORDER:", order)
 	if err != nil {
@@ -105,6 +120,7 @@ func main() {
 	loginScreen = func() {
 		screen := ui.LoginScreen(a, func(phone string) {
 			log.Println("Send verification code to:", phone)
+			forms.SetCurrentUser(a, phone)
 			verifyScreen()
 		})
 		nav.Reset(screen)
@@ -113,7 +129,7 @@ func main() {
 	verifyScreen = func() {
 		content := ui.VerifyScreen(a, func(code string) {
 			log.Println("Verified:", code)
-			forms.StartAutoSync(a, "https://example.com/api/forms/submit")
+			forms.StartAutoSync(a, "https://example.com/api/forms/submit", allForms)
 			dashboardScreen()
 		})
 		// back := widget.NewButton("← Back", func() { nav.PopSlide() })
@@ -125,7 +141,7 @@ func main() {
 		banner := statusBanner(source)
 		content := ui.DashboardScreen(a, allForms, order, banner, func(name string) {
 			formFields := allForms[name]
-			formContent := forms.BuildForm(a, name, formFields.Sections, func(data map[string]string) {
+			formContent := forms.BuildForm(a, name, formFields, func(data map[string]any) {
 				log.Println("Submitted", name, data)
 				nav.PopSlide()
 			})
@@ -0,0 +1,164 @@
+// Command xform converts forms between the forms.json bundle format and ODK
+// XForms, for use with ODK Collect and ODK Central.
+//
+//	xform export -form CASES -o cases.xml internal/forms/assets/forms.json
+//	xform import -o cases.json cases.xml
+//
+// Anything that couldn't be converted is listed on stderr. Imported forms
+// are written as a bundle holding just that form, keyed by the XForm's ID
+// unless -id is given.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"forms-app/internal/forms"
+	"forms-app/internal/xform"
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+	switch flag.Arg(0) {
+	case "export":
+		export(flag.Args()[1:])
+	case "import":
+		importXForm(flag.Args()[1:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: xform export -form CODE [-o file.xml] forms.json")
+	fmt.Fprintln(os.Stderr, "       xform import [-id CODE] [-o file.json] form.xml")
+}
+
+func export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	code := fs.String("form", "", "the form to export, by its key in the bundle")
+	out := fs.String("o", "", "write the XForm to this file instead of stdout")
+	fs.Usage = usage
+	fs.Parse(args)
+	if fs.NArg() != 1 || *code == "" {
+		usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	var bundle forms.FormBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		fatal(fmt.Errorf("%s: %w", fs.Arg(0), err))
+	}
+	def, ok := bundle.Forms[*code]
+	if !ok {
+		fatal(fmt.Errorf("%s has no form %q", fs.Arg(0), *code))
+	}
+	if def.DefaultLocale == "" {
+		def.DefaultLocale = bundle.DefaultLocale
+	}
+	for i := range def.Sections {
+		for j, f := range def.Sections[i].Fields {
+			if list, ok := bundle.Choices[f.ChoiceList]; ok && f.ChoiceList != "" {
+				def.Sections[i].Fields[j].Choices = list
+			}
+		}
+	}
+
+	xml, problems, err := xform.Export(xform.Form{ID: *code, Version: bundle.Version, Definition: def})
+	if err != nil {
+		fatal(err)
+	}
+	for _, p := range problems {
+		fmt.Fprintln(os.Stderr, p)
+	}
+	write(*out, xml)
+}
+
+func importXForm(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	out := fs.String("o", "", "write the bundle to this file instead of stdout")
+	id := fs.String("id", "", "key the form by this ID instead of the XForm's")
+	fs.Usage = usage
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	defer f.Close()
+	form, problems, err := xform.Import(f)
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", fs.Arg(0), err))
+	}
+	for _, p := range problems {
+		fmt.Fprintln(os.Stderr, p)
+	}
+	key := form.ID
+	if *id != "" {
+		key = *id
+	}
+	if key == "" {
+		fatal(fmt.Errorf("%s has no form ID; use -id to give it one", fs.Arg(0)))
+	}
+
+	// named choice lists go back to the bundle level
+	bundle := forms.FormBundle{
+		Version:   form.Version,
+		FormOrder: []string{key},
+		Forms:     map[string]forms.FormDefinition{key: form.Definition},
+	}
+	for _, sec := range form.Definition.Sections {
+		for j, fld := range sec.Fields {
+			if fld.ChoiceList == "" || len(fld.Choices) == 0 {
+				continue
+			}
+			if bundle.Choices == nil {
+				bundle.Choices = make(map[string][]forms.Choice)
+			}
+			bundle.Choices[fld.ChoiceList] = fld.Choices
+			sec.Fields[j].Choices = nil
+		}
+	}
+
+	// keep expressions readable: "a < b", not "a \u003c b"
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bundle); err != nil {
+		fatal(err)
+	}
+	write(*out, buf.Bytes())
+}
+
+func write(path string, data []byte) {
+	if path == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "xform:", err)
+	os.Exit(1)
+}
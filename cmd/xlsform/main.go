@@ -0,0 +1,120 @@
+// Command xlsform converts an XLSForm, exported as survey.csv, choices.csv
+// and settings.csv in one directory, into the forms.json bundle format.
+//
+//	xlsform -o malaria.json exports/malaria
+//	xlsform -into internal/forms/assets/forms.json exports/malaria
+//
+// Anything that couldn't be mapped is listed on stderr. With -into, the form
+// and its choice lists are added to an existing bundle.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"forms-app/internal/forms"
+	"forms-app/internal/xlsform"
+)
+
+func main() {
+	out := flag.String("o", "", "write the bundle to this file instead of stdout")
+	into := flag.String("into", "", "add the form to this existing bundle file")
+	id := flag.String("id", "", "key the form by this ID instead of its form_id setting")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: xlsform [-o file | -into forms.json] [-id CODE] dir")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || (*out != "" && *into != "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	imported, problems, err := xlsform.ImportDir(flag.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	for _, p := range problems {
+		fmt.Fprintln(os.Stderr, p)
+	}
+	if *id != "" {
+		for key, def := range imported.Forms {
+			delete(imported.Forms, key)
+			imported.Forms[*id] = def
+		}
+		imported.FormOrder = []string{*id}
+	}
+
+	bundle := imported
+	target := *out
+	if *into != "" {
+		target = *into
+		if bundle, err = merge(*into, imported); err != nil {
+			fatal(err)
+		}
+	}
+
+	// keep expressions readable: "a < b", not "a \u003c b"
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(bundle); err != nil {
+		fatal(err)
+	}
+	data := buf.Bytes()
+	if target == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		fatal(err)
+	}
+}
+
+// merge adds the imported form and its choice lists to the bundle in path.
+// Replacing an existing form or a different choice list of the same name is
+// refused rather than silently overwriting it.
+func merge(path string, imported forms.FormBundle) (forms.FormBundle, error) {
+	var bundle forms.FormBundle
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return bundle, err
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return bundle, fmt.Errorf("%s: %w", path, err)
+	}
+	if bundle.Forms == nil {
+		bundle.Forms = make(map[string]forms.FormDefinition)
+	}
+	for key, def := range imported.Forms {
+		if _, ok := bundle.Forms[key]; ok {
+			return bundle, fmt.Errorf("%s already has a form %q; use -id to import it under another key", path, key)
+		}
+		bundle.Forms[key] = def
+		bundle.FormOrder = append(bundle.FormOrder, key)
+	}
+	for name, list := range imported.Choices {
+		if existing, ok := bundle.Choices[name]; ok {
+			a, _ := json.Marshal(existing)
+			b, _ := json.Marshal(list)
+			if string(a) != string(b) {
+				return bundle, fmt.Errorf("%s already has a different choice list %q", path, name)
+			}
+			continue
+		}
+		if bundle.Choices == nil {
+			bundle.Choices = make(map[string][]forms.Choice)
+		}
+		bundle.Choices[name] = list
+	}
+	return bundle, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "xlsform:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,344 @@
+package forms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// attachmentDir is where attachment copies live, next to the drafts that
+// reference them.
+func attachmentDir(a fyne.App) string {
+	return filepath.Join(getDraftDir(a), "attachments")
+}
+
+// detectMimeType guesses a file's MIME type from its extension, falling back
+// to sniffing its first bytes.
+func detectMimeType(name string, head []byte) string {
+	t := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+	if t == "" {
+		t = http.DetectContentType(head)
+	}
+	if i := strings.Index(t, ";"); i >= 0 {
+		t = t[:i]
+	}
+	return strings.TrimSpace(t)
+}
+
+// mimeAllowed reports whether t matches one of the allowed types; "image/*"
+// style wildcards are supported and an empty list allows everything.
+func mimeAllowed(t string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == t || (strings.HasSuffix(a, "/*") && strings.HasPrefix(t, strings.TrimSuffix(a, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func readHead(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return head[:n], nil
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+// attachmentName strips the timestamp prefix added when the file was copied.
+func attachmentName(path string) string {
+	base := filepath.Base(path)
+	if i := strings.Index(base, "-"); i > 0 {
+		return base[i+1:]
+	}
+	return base
+}
+
+// attachmentValue is the payload object of an attachment field. The path is
+// local to the device: SubmitForm swaps it for the multipart part name.
+func attachmentValue(path string) map[string]any {
+	v := map[string]any{
+		"name": attachmentName(path),
+		"path": path,
+	}
+	if info, err := os.Stat(path); err == nil {
+		v["size"] = info.Size()
+	}
+	head, _ := readHead(path)
+	v["mimeType"] = detectMimeType(path, head)
+	return v
+}
+
+// AttachmentFile is what the checks of an attachment field need to know
+// about its file.
+type AttachmentFile struct {
+	Name string // file name; its extension gives the type
+	Size int64
+	Head []byte // first bytes, for names without a known extension
+}
+
+// AttachmentFiles finds the file an attachment value refers to, for the
+// missing file, size and type checks: a path on this device in the app, or
+// the name of a multipart part on a server.
+type AttachmentFiles interface {
+	Lookup(value string) (AttachmentFile, error)
+}
+
+// LocalFiles looks attachment values up as paths on this device, as the
+// form screen does.
+type LocalFiles struct{}
+
+func (LocalFiles) Lookup(path string) (AttachmentFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return AttachmentFile{}, err
+	}
+	head, err := readHead(path)
+	if err != nil {
+		return AttachmentFile{}, err
+	}
+	return AttachmentFile{Name: path, Size: info.Size(), Head: head}, nil
+}
+
+// isAttachmentValue recognises attachment objects, typed or decoded from a draft.
+func isAttachmentValue(m map[string]any) (string, bool) {
+	path, ok := m["path"].(string)
+	if !ok || path == "" {
+		return "", false
+	}
+	_, hasName := m["name"]
+	return path, hasName
+}
+
+// attachmentInput is the attachment field widget: a file-open button that
+// copies the chosen file into app storage, and the name of the current file.
+// A copy that is replaced or cleared is deleted unless a draft refers to it.
+type attachmentInput struct {
+	app     fyne.App
+	path    string
+	name    *widget.Label
+	clear   *widget.Button
+	content fyne.CanvasObject
+}
+
+func newAttachmentInput(a fyne.App, f Field, onChanged func()) *attachmentInput {
+	in := &attachmentInput{app: a, name: widget.NewLabel("No file chosen")}
+	limits := f.Validation.limits()
+	win := a.Driver().AllWindows()[0]
+
+	choose := widget.NewButtonWithIcon("Choose file…", theme.FileIcon(), func() {
+		open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, win)
+				return
+			}
+			if r == nil {
+				return // cancelled
+			}
+			defer r.Close()
+			path, err := storeAttachment(a, limits, r)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("Cannot attach file: %v", err), win)
+				return
+			}
+			in.replace(path)
+			onChanged()
+		}, win)
+		var exact []string
+		for _, t := range limits.AllowedTypes {
+			if !strings.HasSuffix(t, "/*") {
+				exact = append(exact, t)
+			}
+		}
+		if len(exact) == len(limits.AllowedTypes) && len(exact) > 0 {
+			open.SetFilter(storage.NewMimeTypeFileFilter(exact))
+		}
+		open.Show()
+	})
+
+	in.clear = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		in.replace("")
+		onChanged()
+	})
+	in.clear.Importance = widget.LowImportance
+	in.clear.Hide()
+
+	in.content = container.NewBorder(nil, nil, choose, in.clear, in.name)
+	return in
+}
+
+// storeAttachment copies the chosen file into app storage after checking the
+// field's size and type limits, and returns the path of the copy.
+func storeAttachment(a fyne.App, v Validation, r fyne.URIReadCloser) (string, error) {
+	limit := v.MaxSize
+	var data []byte
+	var err error
+	if limit > 0 {
+		data, err = io.ReadAll(io.LimitReader(r, limit+1))
+	} else {
+		data, err = io.ReadAll(r)
+	}
+	if err != nil {
+		return "", err
+	}
+	if limit > 0 && int64(len(data)) > limit {
+		return "", fmt.Errorf("file is larger than %s", formatSize(limit))
+	}
+
+	name := r.URI().Name()
+	if t := detectMimeType(name, data); !mimeAllowed(t, v.AllowedTypes) {
+		return "", fmt.Errorf("file type %s is not allowed", t)
+	}
+
+	dir := attachmentDir(a)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("cannot create attachment directory: %v", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d-%s", time.Now().UnixNano(), name))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// replace shows the user's new choice and deletes the copy it replaces.
+func (in *attachmentInput) replace(path string) {
+	if old := in.path; old != "" && old != path {
+		removeAttachments(in.app, []attachmentPart{{path: old}})
+	}
+	in.SetValue(path)
+}
+
+// Value returns the path of the stored copy, or "" when no file is attached.
+func (in *attachmentInput) Value() string {
+	return in.path
+}
+
+func (in *attachmentInput) SetValue(path string) {
+	in.path = path
+	if path == "" {
+		in.name.SetText("No file chosen")
+		in.clear.Hide()
+		return
+	}
+	in.name.SetText(attachmentName(path))
+	in.clear.Show()
+}
+
+// attachmentPart is a file uploaded alongside the JSON payload.
+type attachmentPart struct {
+	part string // multipart field name, e.g. "lab_result" or "patients[0].xray"
+	name string
+	path string
+}
+
+// stripAttachments returns a copy of the payload whose attachment values name
+// their multipart part instead of a local path, and the files to upload.
+func stripAttachments(data map[string]any, prefix string, parts *[]attachmentPart) map[string]any {
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		switch val := v.(type) {
+		case map[string]any:
+			if path, ok := isAttachmentValue(val); ok {
+				part := prefix + k
+				*parts = append(*parts, attachmentPart{part: part, name: attachmentName(path), path: path})
+				clean := map[string]any{"part": part}
+				for mk, mv := range val {
+					if mk != "path" {
+						clean[mk] = mv
+					}
+				}
+				out[k] = clean
+				continue
+			}
+			out[k] = val
+		case []map[string]any:
+			rows := make([]map[string]any, len(val))
+			for i, row := range val {
+				rows[i] = stripAttachments(row, fmt.Sprintf("%s%s[%d].", prefix, k, i), parts)
+			}
+			out[k] = rows
+		case []any:
+			rows := make([]any, len(val))
+			for i, row := range val {
+				if m, ok := row.(map[string]any); ok {
+					rows[i] = stripAttachments(m, fmt.Sprintf("%s%s[%d].", prefix, k, i), parts)
+				} else {
+					rows[i] = row
+				}
+			}
+			out[k] = rows
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// draftAttachments returns the paths of the stored copies that saved drafts
+// still refer to.
+func draftAttachments(a fyne.App) map[string]bool {
+	refs := make(map[string]bool)
+	paths, _ := LoadDrafts(a)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var d Draft
+		if json.Unmarshal(data, &d) != nil {
+			continue
+		}
+		var parts []attachmentPart
+		stripAttachments(d.Data, "", &parts)
+		for _, p := range parts {
+			refs[p.path] = true
+		}
+	}
+	return refs
+}
+
+// removeAttachments deletes the stored copies once they are no longer needed:
+// copies a saved draft still refers to are kept, and so are files outside
+// app storage, which were never copied.
+func removeAttachments(a fyne.App, parts []attachmentPart) {
+	refs := draftAttachments(a)
+	dir := attachmentDir(a)
+	for _, p := range parts {
+		if refs[p.path] || filepath.Dir(p.path) != dir {
+			continue
+		}
+		if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("⚠️ Failed to remove attachment %s: %v\n", p.path, err)
+		}
+	}
+}
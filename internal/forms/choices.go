@@ -0,0 +1,75 @@
+package forms
+
+// prepare readies a loaded bundle for BuildForm: shared choice lists are
+// copied into fields and the bundle's default locale into forms without one.
+func (b *FormBundle) prepare() {
+	b.resolveChoices()
+	for code, def := range b.Forms {
+		if def.DefaultLocale == "" {
+			def.DefaultLocale = b.DefaultLocale
+			b.Forms[code] = def
+		}
+	}
+}
+
+// resolveChoices copies the bundle's shared choice lists into every field
+// that refers to one through ChoiceList.
+func (b *FormBundle) resolveChoices() {
+	if len(b.Choices) == 0 {
+		return
+	}
+	for _, def := range b.Forms {
+		for i := range def.Sections {
+			fields := def.Sections[i].Fields
+			for j := range fields {
+				if list, ok := b.Choices[fields[j].ChoiceList]; ok && fields[j].ChoiceList != "" {
+					fields[j].Choices = list
+				}
+			}
+		}
+	}
+}
+
+// choiceOptions returns every option of a field, from its choices if it has any.
+func choiceOptions(f Field) []string {
+	if len(f.Choices) == 0 {
+		opts := make([]string, len(f.Options))
+		for i, opt := range f.Options {
+			opts[i] = opt.Value
+		}
+		return opts
+	}
+	opts := make([]string, 0, len(f.Choices))
+	for _, c := range f.Choices {
+		opts = append(opts, c.Value)
+	}
+	return opts
+}
+
+// filterChoices returns the options offered for the current value of the
+// field's ChoiceFilter parent. Fields without a filter get all options.
+func filterChoices(f Field, values map[string]string) []string {
+	if f.ChoiceFilter == "" || len(f.Choices) == 0 {
+		return choiceOptions(f)
+	}
+	parent := values[f.ChoiceFilter]
+	opts := []string{}
+	if parent == "" {
+		return opts
+	}
+	for _, c := range f.Choices {
+		if c.Parents[f.ChoiceFilter] == parent {
+			opts = append(opts, c.Value)
+		}
+	}
+	return opts
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
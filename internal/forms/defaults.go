@@ -0,0 +1,53 @@
+package forms
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// defaultCall spots a function call such as "today()" in a default value.
+// Anything else is taken literally, so "2025-01-01" stays a date rather than
+// a subtraction.
+var defaultCall = regexp.MustCompile(`[A-Za-z_]\w*\s*\(`)
+
+// withDefaults returns values plus the default of every field that has no
+// value yet. Defaults may refer to values, including earlier defaults.
+func withDefaults(fields []Field, values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for id, v := range values {
+		out[id] = v
+	}
+	for _, f := range fields {
+		if _, ok := out[f.ID]; ok || f.Default == "" || f.Calculate != "" {
+			continue
+		}
+		if v, ok := defaultValue(f, out); ok {
+			out[f.ID] = v
+		}
+	}
+	return out
+}
+
+// defaultValue evaluates a field's default. Timestamps returned by today()
+// and now() are formatted for temporal and period fields.
+func defaultValue(f Field, values map[string]string) (string, bool) {
+	if !defaultCall.MatchString(f.Default) {
+		return f.Default, true
+	}
+	result, err := evalExpression(f.Default, values)
+	if err != nil {
+		fmt.Printf("⚠️ default for %s %q: %v\n", f.ID, f.Default, err)
+		return "", false
+	}
+	if ts, ok := result.(float64); ok {
+		t := time.Unix(int64(ts), 0).UTC()
+		switch {
+		case isTemporal(f.Type):
+			return formatTemporal(f.Type, t), true
+		case f.Type == "period":
+			return periodOf(f.PeriodType, t).ID(), true
+		}
+	}
+	return formatResult(result), true
+}
@@ -2,7 +2,6 @@ package forms
 
 import (
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -12,7 +11,8 @@ import (
 )
 
 // LoadLatestDraft loads the most recent draft for the given form name.
-func LoadLatestDraft(a fyne.App, formName string) (map[string]string, string) {
+// The returned data keeps the nested draft format and can be passed to BuildForm as prefill.
+func LoadLatestDraft(a fyne.App, formName string) (map[string]any, string) {
 	root := a.Storage().RootURI().Path()
 	if root == "" {
 		home, _ := os.UserHomeDir()
@@ -58,11 +58,9 @@ func LoadLatestDraft(a fyne.App, formName string) (map[string]string, string) {
 	}
 
 	// unwrap nested structure
-	payload := map[string]string{}
-	if d, ok := draft["data"].(map[string]any); ok {
-		for k, v := range d {
-			payload[k] = fmt.Sprintf("%v", v)
-		}
+	payload, _ := draft["data"].(map[string]any)
+	if payload == nil {
+		payload = map[string]any{}
 	}
 
 	return payload, path
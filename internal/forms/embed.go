@@ -12,6 +12,7 @@ var embeddedForms []byte
 func LoadFromEmbedded() (map[string]FormDefinition, []string, error) {
 	var bundle FormBundle
 	if err := json.Unmarshal(embeddedForms, &bundle); err == nil && bundle.Forms != nil {
+		bundle.prepare()
 		return bundle.Forms, bundle.FormOrder, nil
 	}
 
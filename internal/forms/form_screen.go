@@ -1,9 +1,9 @@
 package forms
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -31,54 +31,310 @@ func makeAdaptiveGrid(w fyne.Window, items []fyne.CanvasObject) fyne.CanvasObjec
 	return container.NewGridWithColumns(cols, items...)
 }
 
-// BuildForm builds a form with section tabs.
+// fieldHint renders a field's hint, or returns nil if it has none.
+func fieldHint(f Field, locale Locale) *widget.Label {
+	text := f.Hint.In(locale)
+	if text == "" {
+		return nil
+	}
+	hint := widget.NewLabelWithStyle(text, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+	hint.Wrapping = fyne.TextWrapWord
+	hint.Importance = widget.LowImportance
+	return hint
+}
+
+// fieldHeader renders a field's label, followed by its hint.
+func fieldHeader(f Field, locale Locale) fyne.CanvasObject {
+	label := widget.NewLabel(f.Label.In(locale))
+	if hint := fieldHint(f, locale); hint != nil {
+		return container.NewVBox(label, hint)
+	}
+	return label
+}
+
+// valueInput is a composite field widget that doesn't map onto a single Fyne
+// widget (a geo-point, an attachment...) and reads and writes its value as text.
+type valueInput interface {
+	Value() string
+	SetValue(string)
+}
+
+// focusEntry is an entry that reports losing focus, so a field the user
+// leaves without typing is checked too.
+type focusEntry struct {
+	widget.Entry
+	onFocusLost func()
+}
+
+func newFocusEntry() *focusEntry {
+	e := &focusEntry{}
+	e.Wrapping = fyne.TextWrap(fyne.TextTruncateClip)
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *focusEntry) FocusLost() {
+	e.Entry.FocusLost()
+	if e.onFocusLost != nil {
+		e.onFocusLost()
+	}
+}
+
+// fieldWidgets holds the input widgets of one scope of a form, keyed by field
+// ID: the top-level fields, or a single instance of a repeat group.
+type fieldWidgets struct {
+	text     map[string]*widget.Entry
+	selects  map[string]*widget.Select
+	dates    map[string]*widget.DateEntry
+	bools    map[string]*widget.Check
+	multi    map[string]*widget.CheckGroup
+	custom   map[string]valueInput
+	options  map[string]optionLabels // shown text of select options
+	errors   map[string]*widget.Label
+	overlays map[string]*canvas.Rectangle
+	boxes    map[string]fyne.CanvasObject
+	// touched fields have been changed or left, or checked on submit, and
+	// show their errors as soon as they are found
+	touched map[string]bool
+	last    map[string]string // values seen by the last noteChanges
+}
+
+func newFieldWidgets() *fieldWidgets {
+	return &fieldWidgets{
+		text:     make(map[string]*widget.Entry),
+		selects:  make(map[string]*widget.Select),
+		dates:    make(map[string]*widget.DateEntry),
+		bools:    make(map[string]*widget.Check),
+		multi:    make(map[string]*widget.CheckGroup),
+		custom:   make(map[string]valueInput),
+		options:  make(map[string]optionLabels),
+		errors:   make(map[string]*widget.Label),
+		overlays: make(map[string]*canvas.Rectangle),
+		boxes:    make(map[string]fyne.CanvasObject),
+		touched:  make(map[string]bool),
+	}
+}
+
+// values returns the current value of every widget in the scope. Selects
+// show options in the chosen locale but report their values.
+func (ws *fieldWidgets) values() map[string]string {
+	data := collectData(ws.text, ws.selects, ws.dates, ws.bools, ws.multi, ws.custom)
+	for id, opts := range ws.options {
+		if _, ok := ws.multi[id]; ok {
+			data[id] = joinList(opts.values(splitList(data[id])))
+		} else {
+			data[id] = opts.value(data[id])
+		}
+	}
+	return data
+}
+
+// errorTint and warningTint shade the overlay of a field with an error or a warning.
+var (
+	errorTint   = color.NRGBA{255, 0, 0, 40}
+	warningTint = color.NRGBA{255, 165, 0, 40}
+)
+
+// clearErrors hides all error and warning labels and overlays in the scope.
+func (ws *fieldWidgets) clearErrors() {
+	for id, lbl := range ws.errors {
+		lbl.Hide()
+		if r, ok := ws.overlays[id]; ok {
+			r.Hide()
+			canvas.Refresh(r)
+		}
+	}
+}
+
+// showErrors marks the given fields as invalid, listing each of their errors.
+func (ws *fieldWidgets) showErrors(fieldErrs map[string][]string) {
+	ws.mark(fieldErrs, widget.MediumImportance, errorTint)
+}
+
+// showWarnings marks fields whose answers look unusual, in another colour.
+func (ws *fieldWidgets) showWarnings(fieldWarnings map[string][]string) {
+	ws.mark(fieldWarnings, widget.WarningImportance, warningTint)
+}
+
+func (ws *fieldWidgets) mark(fieldMsgs map[string][]string, importance widget.Importance, tint color.Color) {
+	for id, msgs := range fieldMsgs {
+		if lbl, ok := ws.errors[id]; ok {
+			lbl.Importance = importance
+			lbl.SetText("⚠ " + strings.Join(msgs, "\n⚠ "))
+			lbl.Show()
+		}
+		if r, ok := ws.overlays[id]; ok {
+			r.FillColor = tint
+			r.Show()
+			canvas.Refresh(r)
+		}
+	}
+}
+
+// withError adds the error label of a field below its content and the
+// overlay that tints it, and returns the field's box.
+func (ws *fieldWidgets) withError(f Field, content *fyne.Container) fyne.CanvasObject {
+	errLbl := widget.NewLabel("")
+	errLbl.TextStyle = fyne.TextStyle{Italic: true}
+	errLbl.Hide()
+	ws.errors[f.ID] = errLbl
+	content.Add(errLbl)
+
+	overlay := canvas.NewRectangle(errorTint)
+	overlay.Hide()
+	ws.overlays[f.ID] = overlay
+	return container.NewStack(content, overlay)
+}
+
+// noteChanges marks the fields whose value differs from the last call as
+// touched; the first call only records the values.
+func (ws *fieldWidgets) noteChanges(values map[string]string) {
+	if ws.last != nil {
+		for id, v := range values {
+			if ws.last[id] != v {
+				ws.touched[id] = true
+			}
+		}
+	}
+	ws.last = make(map[string]string, len(values))
+	for id, v := range values {
+		ws.last[id] = v
+	}
+}
+
+// touch marks fields as touched, e.g. once they have been checked on submit.
+func (ws *fieldWidgets) touch(fields []Field) {
+	for _, f := range fields {
+		ws.touched[f.ID] = true
+	}
+}
+
+// showTouched marks the errors and warnings of touched fields and clears
+// every other mark in the scope.
+func (ws *fieldWidgets) showTouched(fieldErrs, fieldWarnings map[string][]string) {
+	touched := func(msgs map[string][]string) map[string][]string {
+		out := make(map[string][]string)
+		for id, msg := range msgs {
+			if ws.touched[id] {
+				out[id] = msg
+			}
+		}
+		return out
+	}
+	ws.clearErrors()
+	ws.showErrors(touched(fieldErrs))
+	ws.showWarnings(touched(fieldWarnings))
+}
+
+// countMessages counts the errors or warnings of all fields.
+func countMessages(fieldMsgs map[string][]string) int {
+	n := 0
+	for _, msgs := range fieldMsgs {
+		n += len(msgs)
+	}
+	return n
+}
+
+// repeatInstance is one entry of a repeat group section.
+type repeatInstance struct {
+	ws     *fieldWidgets
+	box    fyne.CanvasObject
+	title  *widget.Label
+	remove *widget.Button
+}
+
+// BuildForm builds a form with section tabs, or as a wizard if def.Layout is
+// "wizard", in the locale chosen with SetLocale.
 // Supports "grid"/"stack" layouts, responsive columns, and auto-hides tabs if only one section.
+// Repeat sections render a list of instances with add/remove controls.
 func BuildForm(
 	a fyne.App,
 	formName string,
-	sections []Section,
-	onSubmit func(data map[string]string),
-	prefill ...map[string]string, // optional prefill values
+	def FormDefinition,
+	onSubmit func(data map[string]any),
+	prefill ...map[string]any, // optional prefill values (draft "data")
 ) fyne.CanvasObject {
+	sections := def.Sections
 	var values map[string]string
+	var repeatValues map[string][]map[string]string
 	if len(prefill) > 0 {
-		values = prefill[0]
+		values, repeatValues = splitPayload(prefill[0])
+	}
+
+	locale := def.Locale(CurrentLocale(a))
+	top := newFieldWidgets()
+	repeats := make(map[int][]*repeatInstance)
+
+	// onChange recomputes calculated fields and re-applies relevance; it is
+	// wired up once all widgets exist, so prefilling values below doesn't trigger it.
+	var onChange func()
+	refreshing := false
+	changed := func() {
+		// widgets updated by onChange itself (cleared choices) report back here
+		if onChange == nil || refreshing {
+			return
+		}
+		refreshing = true
+		onChange()
+		refreshing = false
+	}
+
+	// leave marks a field touched once the user leaves it, so a required
+	// field skipped without typing is flagged too.
+	leave := func(ws *fieldWidgets, id string) func() {
+		return func() {
+			if !ws.touched[id] {
+				ws.touched[id] = true
+				changed()
+			}
+		}
 	}
 
-	allText := make(map[string]*widget.Entry)
-	allSelect := make(map[string]*widget.Select)
-	allDate := make(map[string]*widget.DateEntry)
-	allBool := make(map[string]*widget.Check)
-	errorLabels := make(map[string]*widget.Label)
-	overlayRects := make(map[string]*canvas.Rectangle)
+	// inBounds refuses a picked date or time outside the field's minDate and
+	// maxDate, resolved against now, and tells the user why.
+	inBounds := func(f Field) func(value string) bool {
+		limits := f.Validation.limits()
+		return func(value string) bool {
+			t, err := parseTemporal(f.Type, value)
+			if err != nil {
+				return true // empty or half-picked: validation reports it
+			}
+			if msg := outOfBounds(f.Type, f.Label.In(locale), limits, t, time.Now()); msg != "" {
+				dialog.ShowInformation("Out of range", msg, a.Driver().AllWindows()[0])
+				return false
+			}
+			return true
+		}
+	}
 
-	buildSectionContent := func(sec Section) fyne.CanvasObject {
+	buildFields := func(fields []Field, ws *fieldWidgets, values map[string]string) []fyne.CanvasObject {
 		var items []fyne.CanvasObject
-		for _, f := range sec.Fields {
+		for _, f := range fields {
 			var field fyne.CanvasObject
 
-			switch f.Type {
-			case "text", "number", "multiline":
+			fieldType := f.Type
+			if f.Calculate != "" {
+				fieldType = "calculate"
+			}
+
+			switch fieldType {
+			case "calculate":
+				// read-only: the value always comes from the expression
 				e := widget.NewEntry()
+				e.SetPlaceHolder("Calculated")
+				e.Disable()
+
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), e))
+				ws.text[f.ID] = e
+
+			case "text", "multiline":
+				fe := newFocusEntry()
+				fe.onFocusLost = leave(ws, f.ID)
+				e := &fe.Entry
 				if f.Type == "multiline" {
 					e.MultiLine = true
 				}
-				if f.Type == "number" {
-					e.SetPlaceHolder("Enter number...")
-					e.OnChanged = func(text string) {
-						clean := strings.Map(func(r rune) rune {
-							if (r >= '0' && r <= '9') || r == '.' {
-								return r
-							}
-							return -1
-						}, text)
-						if clean != text {
-							e.SetText(clean)
-						}
-					}
-				}
-				if f.Validation.MaxLength > 0 {
-					maxLen := f.Validation.MaxLength
+				if maxLen := f.Validation.limits().MaxLength; maxLen > 0 {
 					oldHandler := e.OnChanged
 					e.OnChanged = func(text string) {
 						if oldHandler != nil {
@@ -89,77 +345,154 @@ func BuildForm(
 						}
 					}
 				}
+				if placeholder := f.Placeholder.In(locale); placeholder != "" {
+					e.SetPlaceHolder(placeholder)
+				}
+				inner := e.OnChanged
+				e.OnChanged = func(text string) {
+					if inner != nil {
+						inner(text)
+					}
+					changed()
+				}
 				// prefill value
 				if val, ok := values[f.ID]; ok {
 					e.SetText(val)
 				}
 
-				errLbl := widget.NewLabel("")
-				errLbl.TextStyle = fyne.TextStyle{Italic: true}
-				errLbl.Hide()
-				errorLabels[f.ID] = errLbl
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), fe))
+				ws.text[f.ID] = e
 
-				content := container.NewVBox(widget.NewLabel(f.Label), e, errLbl)
-				overlay := canvas.NewRectangle(color.NRGBA{255, 0, 0, 40})
-				overlay.Hide()
-				overlayRects[f.ID] = overlay
-				field = container.NewStack(content, overlay)
-				allText[f.ID] = e
+			case "number", "integer", "decimal":
+				n := newNumberInput(f, locale, changed)
+				n.entry.onFocusLost = leave(ws, f.ID)
+				if val, ok := values[f.ID]; ok {
+					n.SetValue(val)
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), n.content()))
+				ws.custom[f.ID] = n
 
 			case "select":
-				s := widget.NewSelect(f.Options, func(string) {})
+				opts := newOptionLabels(f, locale)
+				s := widget.NewSelect(opts.labels(choiceOptions(f)), func(string) { changed() })
 				s.PlaceHolder = "Select..."
+				if placeholder := f.Placeholder.In(locale); placeholder != "" {
+					s.PlaceHolder = placeholder
+				}
 				if val, ok := values[f.ID]; ok {
-					s.SetSelected(val)
-				}
-				errLbl := widget.NewLabel("")
-				errLbl.TextStyle = fyne.TextStyle{Italic: true}
-				errLbl.Hide()
-				errorLabels[f.ID] = errLbl
-				content := container.NewVBox(widget.NewLabel(f.Label), s, errLbl)
-				overlay := canvas.NewRectangle(color.NRGBA{255, 0, 0, 40})
-				overlay.Hide()
-				overlayRects[f.ID] = overlay
-				field = container.NewStack(content, overlay)
-				allSelect[f.ID] = s
+					s.SetSelected(opts.label(val))
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), s))
+				ws.selects[f.ID] = s
+				ws.options[f.ID] = opts
 
 			case "date":
 				d := widget.NewDateEntry()
-				if val, ok := values[f.ID]; ok && val != "" {
-					if parsed, err := time.Parse("2006-01-02", val); err == nil {
+				if placeholder := f.Placeholder.In(locale); placeholder != "" {
+					d.SetPlaceHolder(placeholder)
+				}
+				pick := boundedPick{accept: inBounds(f)}
+				setDate := func(raw string) {
+					if parsed, err := parseTemporal("date", raw); err == nil {
 						d.SetDate(&parsed)
+					} else {
+						d.SetDate(nil)
+					}
+				}
+				d.OnChanged = func(t *time.Time) {
+					picked := ""
+					if t != nil {
+						picked = formatTemporal("date", *t)
 					}
+					if pick.changed(picked, setDate) {
+						changed()
+					}
+				}
+				if val, ok := values[f.ID]; ok && val != "" {
+					pick.set(val, setDate)
 				}
-				errLbl := widget.NewLabel("")
-				errLbl.TextStyle = fyne.TextStyle{Italic: true}
-				errLbl.Hide()
-				errorLabels[f.ID] = errLbl
-				content := container.NewVBox(widget.NewLabel(f.Label), d, errLbl)
-				overlay := canvas.NewRectangle(color.NRGBA{255, 0, 0, 40})
-				overlay.Hide()
-				overlayRects[f.ID] = overlay
-				field = container.NewStack(content, overlay)
-				allDate[f.ID] = d
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), d))
+				ws.dates[f.ID] = d
 
 			case "boolean":
-				c := widget.NewCheck(f.Label, func(bool) {})
+				c := widget.NewCheck(f.Label.In(locale), func(bool) { changed() })
 				if val, ok := values[f.ID]; ok {
 					c.SetChecked(val == "true" || val == "1" || strings.EqualFold(val, "yes"))
 				}
-				errLbl := widget.NewLabel("")
-				errLbl.TextStyle = fyne.TextStyle{Italic: true}
-				errLbl.Hide()
-				errorLabels[f.ID] = errLbl
-				content := container.NewVBox(c, errLbl)
-				overlay := canvas.NewRectangle(color.NRGBA{255, 0, 0, 40})
-				overlay.Hide()
-				overlayRects[f.ID] = overlay
-				field = container.NewStack(content, overlay)
-				allBool[f.ID] = c
+				content := container.NewVBox(c)
+				if hint := fieldHint(f, locale); hint != nil {
+					content.Add(hint)
+				}
+				field = ws.withError(f, content)
+				ws.bools[f.ID] = c
+
+			case "select_multiple":
+				opts := newOptionLabels(f, locale)
+				cg := widget.NewCheckGroup(opts.labels(choiceOptions(f)), func([]string) { changed() })
+				if val, ok := values[f.ID]; ok {
+					cg.SetSelected(opts.labels(splitList(val)))
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), cg))
+				ws.multi[f.ID] = cg
+				ws.options[f.ID] = opts
+
+			case "geopoint":
+				g := newGeoPointInput(a, DefaultLocationProvider, changed)
+				if val, ok := values[f.ID]; ok {
+					g.SetValue(val)
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), g.content))
+				ws.custom[f.ID] = g
+
+			case "attachment":
+				at := newAttachmentInput(a, f, changed)
+				if val, ok := values[f.ID]; ok {
+					at.SetValue(val)
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), at.content))
+				ws.custom[f.ID] = at
+
+			case "signature":
+				sig := newSignatureInput(a, changed)
+				if val, ok := values[f.ID]; ok {
+					sig.SetValue(val)
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), sig.content))
+				ws.custom[f.ID] = sig
+
+			case "time", "datetime":
+				var in valueInput
+				var input fyne.CanvasObject
+				if f.Type == "time" {
+					t := newTimeInput(changed, inBounds(f))
+					in, input = t, t.content
+				} else {
+					dt := newDateTimeInput(changed, inBounds(f))
+					in, input = dt, dt.content
+				}
+				if val, ok := values[f.ID]; ok {
+					in.SetValue(val)
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), input))
+				ws.custom[f.ID] = in
+
+			case "period":
+				p := newPeriodInput(f, locale, changed)
+				if val, ok := values[f.ID]; ok {
+					p.SetValue(val)
+				}
+				field = ws.withError(f, container.NewVBox(fieldHeader(f, locale), p.content()))
+				ws.custom[f.ID] = p
+			}
+			if field != nil {
+				ws.boxes[f.ID] = field
 			}
 			items = append(items, field)
 		}
+		return items
+	}
 
+	layoutSection := func(sec Section, items []fyne.CanvasObject) fyne.CanvasObject {
 		if strings.ToLower(sec.Layout) == "grid" {
 			if sec.Columns > 0 {
 				return container.NewGridWithColumns(sec.Columns, items...)
@@ -169,91 +502,399 @@ func BuildForm(
 		return container.NewVBox(items...)
 	}
 
+	// buildRepeatContent renders a repeat section as a list of instances,
+	// starting with the drafted ones (or MinRepeat empty ones).
+	buildRepeatContent := func(i int, sec Section) fyne.CanvasObject {
+		list := container.NewVBox()
+		var addBtn *widget.Button
+
+		renumber := func() {
+			count := len(repeats[i])
+			for n, inst := range repeats[i] {
+				inst.title.SetText(fmt.Sprintf("%s #%d", sec.Title.In(locale), n+1))
+				if count <= sec.MinRepeat {
+					inst.remove.Disable()
+				} else {
+					inst.remove.Enable()
+				}
+			}
+			if sec.MaxRepeat > 0 && count >= sec.MaxRepeat {
+				addBtn.Disable()
+			} else {
+				addBtn.Enable()
+			}
+		}
+
+		addInstance := func(vals map[string]string) {
+			inst := &repeatInstance{ws: newFieldWidgets()}
+			inst.title = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			inst.remove = widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				for n, other := range repeats[i] {
+					if other == inst {
+						repeats[i] = append(repeats[i][:n], repeats[i][n+1:]...)
+						break
+					}
+				}
+				list.Remove(inst.box)
+				renumber()
+				changed()
+			})
+			inst.remove.Importance = widget.LowImportance
+			inst.box = container.NewVBox(
+				container.NewBorder(nil, nil, nil, inst.remove, inst.title),
+				layoutSection(sec, buildFields(sec.Fields, inst.ws, withDefaults(sec.Fields, vals))),
+				widget.NewSeparator(),
+			)
+			repeats[i] = append(repeats[i], inst)
+			list.Add(inst.box)
+		}
+
+		addBtn = widget.NewButtonWithIcon("Add "+sec.Title.In(locale), theme.ContentAddIcon(), func() {
+			addInstance(nil)
+			renumber()
+			changed()
+		})
+
+		drafted := repeatValues[sec.Key()]
+		for _, vals := range drafted {
+			addInstance(vals)
+		}
+		for n := len(drafted); n < sec.MinRepeat; n++ {
+			addInstance(nil)
+		}
+		renumber()
+
+		return container.NewVBox(list, addBtn)
+	}
+
+	buildSectionContent := func(i int, sec Section) fyne.CanvasObject {
+		if sec.Repeat {
+			return buildRepeatContent(i, sec)
+		}
+		sectionValues := withDefaults(sec.Fields, withStickyValues(a, sec.Fields, values))
+		return layoutSection(sec, buildFields(sec.Fields, top, sectionValues))
+	}
+
 	var formContent fyne.CanvasObject
+	sectionBoxes := make([]fyne.CanvasObject, len(sections))
+	var tabs *container.AppTabs
+	wizard := def.Layout == "wizard" && len(sections) > 1
 	if len(sections) == 1 {
-		formContent = buildSectionContent(sections[0])
+		sectionBoxes[0] = buildSectionContent(0, sections[0])
+		formContent = sectionBoxes[0]
+	} else if wizard {
+		// pages are shown one at a time by showPage below
+		for i, sec := range sections {
+			sectionBoxes[i] = buildSectionContent(i, sec)
+		}
 	} else {
-		tabs := container.NewAppTabs()
-		for _, sec := range sections {
-			tab := container.NewTabItem(sec.Title, buildSectionContent(sec))
+		tabs = container.NewAppTabs()
+		for i, sec := range sections {
+			sectionBoxes[i] = buildSectionContent(i, sec)
+			tab := container.NewTabItem(sec.Title.In(locale), sectionBoxes[i])
 			tabs.Append(tab)
 		}
 		formContent = tabs
 	}
 
-	submit := widget.NewButton("Submit", func() {
-		for id, lbl := range errorLabels {
-			lbl.Hide()
-			if r, ok := overlayRects[id]; ok {
-				r.Hide()
-				canvas.Refresh(r)
+	var topFields []Field
+	for _, sec := range sections {
+		if !sec.Repeat {
+			topFields = append(topFields, sec.Fields...)
+		}
+	}
+
+	// recalculate updates values and the widgets of calculated fields.
+	recalculate := func(fields []Field, ws *fieldWidgets, values map[string]string) {
+		calculate(fields, values)
+		for _, f := range fields {
+			if e := ws.text[f.ID]; f.Calculate != "" && e.Text != values[f.ID] {
+				e.SetText(values[f.ID])
+			}
+		}
+	}
+
+	// formValues gathers the values of every scope for validateValues.
+	formValues := func() map[string]string {
+		rows := make(map[string][]map[string]string)
+		for i, sec := range sections {
+			for _, inst := range repeats[i] {
+				rows[sec.Key()] = append(rows[sec.Key()], inst.ws.values())
+			}
+		}
+		return flattenValues(top.values(), rows)
+	}
+
+	// refilterChoices narrows cascading selects to the options matching their
+	// parent's value, clearing answers that no longer match. Fields are
+	// handled in form order, so a cleared parent empties its children too.
+	refilterChoices := func(fields []Field, ws *fieldWidgets, values map[string]string) {
+		for _, f := range fields {
+			if f.ChoiceFilter == "" {
+				continue
+			}
+			opts := ws.options[f.ID].labels(filterChoices(f, values))
+			if s, ok := ws.selects[f.ID]; ok {
+				s.Options = opts
+				s.Refresh()
+				if s.Selected != "" && !containsString(opts, s.Selected) {
+					s.ClearSelected()
+					values[f.ID] = ""
+				}
+			}
+			if cg, ok := ws.multi[f.ID]; ok {
+				var kept []string
+				for _, v := range cg.Selected {
+					if containsString(opts, v) {
+						kept = append(kept, v)
+					}
+				}
+				cg.Options = opts
+				if len(kept) != len(cg.Selected) {
+					cg.SetSelected(kept)
+					values[f.ID] = joinList(ws.options[f.ID].values(kept))
+				}
+				cg.Refresh()
+			}
+		}
+	}
+
+	// showRelevant hides fields whose relevance expression is false.
+	showRelevant := func(fields []Field, ws *fieldWidgets, values map[string]string, secShown bool) {
+		for _, f := range fields {
+			box, ok := ws.boxes[f.ID]
+			if !ok {
+				continue
+			}
+			if secShown && isRelevant(f.Relevant, values) {
+				box.Show()
+			} else {
+				box.Hide()
+			}
+		}
+	}
+
+	var submit *widget.Button
+	errorCount := 0
+	// submitText shows how many errors remain, touched or not.
+	submitText := func() string {
+		switch errorCount {
+		case 0:
+			return "Submit"
+		case 1:
+			return "Submit (1 error)"
+		}
+		return fmt.Sprintf("Submit (%d errors)", errorCount)
+	}
+
+	// Repeat instances see the top-level values plus their own, so their
+	// expressions can refer to both. Every rule re-runs on each change, so
+	// touched fields show their errors live, and a cross-field rule follows
+	// the field it refers to as well as its own.
+	onChange = func() {
+		values := top.values()
+		refilterChoices(topFields, top, values)
+		recalculate(topFields, top, values)
+		for i, sec := range sections {
+			secShown := isRelevant(sec.Relevant, values)
+			if secShown {
+				sectionBoxes[i].Show()
+			} else {
+				sectionBoxes[i].Hide()
+			}
+			if tabs != nil {
+				if secShown {
+					tabs.EnableIndex(i)
+				} else {
+					tabs.DisableIndex(i)
+				}
+			}
+			if !sec.Repeat {
+				showRelevant(sec.Fields, top, values, secShown)
+				continue
+			}
+			for _, inst := range repeats[i] {
+				scoped := mergeValues(values, inst.ws.values())
+				refilterChoices(sec.Fields, inst.ws, scoped)
+				recalculate(sec.Fields, inst.ws, scoped)
+				showRelevant(sec.Fields, inst.ws, scoped, true)
+				inst.ws.noteChanges(inst.ws.values())
+			}
+		}
+		top.noteChanges(values)
+
+		res := validateValues(def, formValues(), LocalFiles{}, locale)
+		top.showTouched(res.scope(""))
+		for i, sec := range sections {
+			for n, inst := range repeats[i] {
+				inst.ws.showTouched(res.scope(instanceKey(sec.Key(), n)))
 			}
 		}
+		errorCount = countMessages(res.Errors)
+		if submit != nil {
+			submit.SetText(submitText())
+		}
+	}
+	changed()
 
-		data := map[string]string{}
-		for k, v := range allText {
-			data[k] = v.Text
+	// draftData gathers all values, shown or not, in the nested payload format.
+	draftData := func() map[string]any {
+		data := typedValues(topFields, top.values())
+		for i, sec := range sections {
+			if !sec.Repeat {
+				continue
+			}
+			rows := []map[string]any{}
+			for _, inst := range repeats[i] {
+				rows = append(rows, typedValues(sec.Fields, inst.ws.values()))
+			}
+			data[sec.Key()] = rows
 		}
-		for k, v := range allSelect {
-			data[k] = v.Selected
+		return data
+	}
+
+	// checkSections validates the shown fields of the given sections, marking
+	// the invalid and unusual ones, and returns their data for submission and
+	// their warnings. Cross-field rules see every shown field, so a wizard
+	// page can be checked on its own. A repeat count out of bounds can't be
+	// marked on a field and is returned as an error instead.
+	checkSections := func(secs ...int) (data map[string]any, warnings []warning, invalid bool, err error) {
+		top.clearErrors()
+		for _, insts := range repeats {
+			for _, inst := range insts {
+				inst.ws.clearErrors()
+			}
 		}
-		for k, v := range allDate {
-			if v.Date != nil {
-				data[k] = v.Date.Format("2006-01-02")
+
+		values := top.values()
+		res := validateValues(def, formValues(), LocalFiles{}, locale)
+
+		// Hidden fields are neither validated nor submitted
+		shownFields := relevantFields(sections, values)
+		checkedIDs := make(map[string]bool)
+		for _, i := range secs {
+			for _, f := range sections[i].Fields {
+				checkedIDs[f.ID] = !sections[i].Repeat
 			}
 		}
-		for k, v := range allBool {
-			data[k] = strconv.FormatBool(v.Checked)
+		var checked []Field
+		for _, f := range shownFields {
+			if checkedIDs[f.ID] {
+				checked = append(checked, f)
+			}
 		}
+		data = typedValues(checked, values)
+		top.touch(checked)
 
-		var allFields []Field
-		for _, sec := range sections {
-			allFields = append(allFields, sec.Fields...)
+		fieldErrs, fieldWarnings := res.scope("")
+		for id := range fieldErrs {
+			if !checkedIDs[id] {
+				delete(fieldErrs, id)
+			}
+		}
+		top.showErrors(fieldErrs)
+		invalid = len(fieldErrs) > 0
+		for _, f := range checked {
+			if msgs, ok := fieldWarnings[f.ID]; ok {
+				top.showWarnings(map[string][]string{f.ID: msgs})
+				for _, msg := range msgs {
+					warnings = append(warnings, warning{Field: f.ID, Message: msg})
+				}
+			}
 		}
 
-		fieldErrs, err := validateForm(allFields, allText, allSelect, allDate, allBool)
-		if err != nil && len(fieldErrs) > 0 {
-			for id, msg := range fieldErrs {
-				if lbl, ok := errorLabels[id]; ok {
-					lbl.SetText("⚠ " + msg)
-					lbl.Show()
+		// Each repeat instance is validated and submitted on its own
+		for _, i := range secs {
+			sec := sections[i]
+			if !sec.Repeat || !isRelevant(sec.Relevant, values) {
+				continue
+			}
+			if msgs := res.Errors[sec.Key()]; len(msgs) > 0 {
+				return nil, nil, invalid, errors.New(msgs[0])
+			}
+			rows := []map[string]any{}
+			for n, inst := range repeats[i] {
+				instValues := inst.ws.values()
+				instFields := shownInstanceFields(sec, mergeValues(values, instValues))
+				inst.ws.touch(instFields)
+				prefix := instanceKey(sec.Key(), n)
+				fieldErrs, fieldWarnings := res.scope(prefix)
+				if len(fieldErrs) > 0 {
+					inst.ws.showErrors(fieldErrs)
+					invalid = true
 				}
-				if r, ok := overlayRects[id]; ok {
-					r.Show()
-					canvas.Refresh(r)
+				inst.ws.showWarnings(fieldWarnings)
+				for _, f := range instFields {
+					for _, msg := range fieldWarnings[f.ID] {
+						warnings = append(warnings, warning{Field: prefix + f.ID, Message: msg})
+					}
 				}
+				rows = append(rows, typedValues(instFields, instValues))
 			}
+			data[sec.Key()] = rows
+		}
+		return data, warnings, invalid, nil
+	}
+
+	allSections := make([]int, len(sections))
+	for i := range sections {
+		allSections[i] = i
+	}
+
+	submit = widget.NewButton(submitText(), func() {
+		data, warnings, invalid, err := checkSections(allSections...)
+		if err != nil {
+			dialog.ShowError(err, a.Driver().AllWindows()[0])
+			return
+		}
+		if invalid {
 			dialog.ShowError(fmt.Errorf("Please correct the highlighted fields."), a.Driver().AllWindows()[0])
 			return
 		}
 
-		apiURL := "https://example.com/api/forms/submit"
-		go func() {
-			err := SubmitForm(a, apiURL, formName, data)
-			fyne.Do(func() {
-				if err != nil {
-					if strings.Contains(err.Error(), "offline mode") {
-						dialog.ShowInformation("📥 Saved Offline",
-							"No network — form stored locally for later upload.",
-							a.Driver().AllWindows()[0])
-					} else {
-						dialog.ShowError(fmt.Errorf("Submission failed: %v", err),
-							a.Driver().AllWindows()[0])
+		values := top.values()
+		shownFields := relevantFields(sections, values)
+
+		send := func() {
+			apiURL := "https://example.com/api/forms/submit"
+			go func() {
+				err := SubmitForm(a, apiURL, formName, data)
+				fyne.Do(func() {
+					if err != nil {
+						if strings.Contains(err.Error(), "offline mode") {
+							dialog.ShowInformation("📥 Saved Offline",
+								"No network — form stored locally for later upload.",
+								a.Driver().AllWindows()[0])
+						} else {
+							dialog.ShowError(fmt.Errorf("Submission failed: %v", err),
+								a.Driver().AllWindows()[0])
+						}
+						return
 					}
-					return
-				}
-				dialog.ShowInformation("✅ Success",
-					"Form submitted successfully!",
-					a.Driver().AllWindows()[0])
-				onSubmit(data)
-			})
-		}()
+					rememberStickyValues(a, shownFields, values)
+					dialog.ShowInformation("✅ Success",
+						"Form submitted successfully!",
+						a.Driver().AllWindows()[0])
+					onSubmit(data)
+				})
+			}()
+		}
+		if len(warnings) == 0 {
+			send()
+			return
+		}
+		// warnings don't block submission, but the user confirms them and
+		// the confirmed list is sent along
+		confirmWarnings(warnings, a.Driver().AllWindows()[0], func() {
+			data[warningsKey] = warnings
+			send()
+		})
 	})
 
 	saveBtn := widget.NewButton("💾 Save Draft", func() {
 		payload := map[string]any{
 			"form":      formName,
-			"data":      collectData(allText, allSelect, allDate, allBool),
+			"data":      draftData(),
 			"timestamp": time.Now().Format(time.RFC3339),
 		}
 		if err := SaveTaggedDraft(a, formName, payload); err != nil {
@@ -264,6 +905,30 @@ func BuildForm(
 	})
 
 	buttons := container.NewGridWithColumns(2, submit, saveBtn)
+	if wizard {
+		formContent, buttons = buildWizard(def, locale, wizardPages{
+			sections: sectionBoxes,
+			values:   top.values,
+			check: func(i int) bool {
+				_, _, invalid, err := checkSections(i)
+				if err != nil {
+					dialog.ShowError(err, a.Driver().AllWindows()[0])
+					return false
+				}
+				if invalid {
+					dialog.ShowError(fmt.Errorf("Please correct the highlighted fields."), a.Driver().AllWindows()[0])
+				}
+				return !invalid
+			},
+			review: func(i int) fyne.CanvasObject {
+				values := top.values()
+				if sections[i].Repeat {
+					return reviewRepeat(sections[i], repeats[i], values, locale)
+				}
+				return reviewFields(sections[i].Fields, top, values, locale)
+			},
+		}, submit, saveBtn)
+	}
 
 	return container.NewBorder(
 		nil,
@@ -282,6 +947,8 @@ func collectData(
 	allSelect map[string]*widget.Select,
 	allDate map[string]*widget.DateEntry,
 	allBool map[string]*widget.Check,
+	allMulti map[string]*widget.CheckGroup,
+	allCustom map[string]valueInput,
 ) map[string]string {
 	data := map[string]string{}
 	for k, v := range allText {
@@ -292,217 +959,83 @@ func collectData(
 	}
 	for k, v := range allDate {
 		if v.Date != nil {
-			data[k] = v.Date.Format("2006-01-02")
+			data[k] = formatTemporal("date", *v.Date)
 		}
 	}
 	for k, v := range allBool {
 		data[k] = strconv.FormatBool(v.Checked)
 	}
+	for k, v := range allMulti {
+		data[k] = joinList(v.Selected)
+	}
+	for k, v := range allCustom {
+		data[k] = v.Value()
+	}
 	return data
 }
 
-// validateForm applies validation rules to all field types.
-func validateForm(
-	fields []Field,
-	textEntries map[string]*widget.Entry,
-	selectEntries map[string]*widget.Select,
-	dateEntries map[string]*widget.DateEntry,
-	boolEntries map[string]*widget.Check,
-) (map[string]string, error) {
-	fieldErrors := make(map[string]string)
-	values := make(map[string]string)
-
-	// Build a map of all values for cross-field access
-	for _, f := range fields {
-		if e, ok := textEntries[f.ID]; ok {
-			values[f.ID] = e.Text
-		} else if s, ok := selectEntries[f.ID]; ok {
-			values[f.ID] = s.Selected
-		} else if d, ok := dateEntries[f.ID]; ok && d.Date != nil {
-			values[f.ID] = d.Date.Format("2006-01-02")
-		} else if c, ok := boolEntries[f.ID]; ok {
-			values[f.ID] = strconv.FormatBool(c.Checked)
-		}
+// evalFormula safely evaluates a logical or arithmetic formula and returns whether it’s true.
+func evalFormula(expr string, values map[string]string) (bool, error) {
+	result, err := evalExpression(expr, values)
+	if err != nil {
+		return false, err
 	}
 
-	for _, f := range fields {
-		v := f.Validation
-		val := values[f.ID]
-
-		// ---------- Required ----------
-		if v.Required {
-			switch f.Type {
-			case "boolean":
-				if val == "false" {
-					fieldErrors[f.ID] = fmt.Sprintf("'%s' must be checked to proceed", f.Label)
-				}
-			default:
-				if val == "" {
-					fieldErrors[f.ID] = fmt.Sprintf("'%s' is required", f.Label)
-				}
-			}
-		}
-
-		// ---------- Length validation ----------
-		if v.MinLength > 0 && len(val) < v.MinLength {
-			fieldErrors[f.ID] = fmt.Sprintf("'%s' must be at least %d characters", f.Label, v.MinLength)
-		}
-		if v.MaxLength > 0 && len(val) > v.MaxLength {
-			fieldErrors[f.ID] = fmt.Sprintf("'%s' must be no more than %d characters", f.Label, v.MaxLength)
-		}
-
-		// ---------- Numeric range ----------
-		if f.Type == "number" && val != "" {
-			num, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				fieldErrors[f.ID] = fmt.Sprintf("'%s' must be numeric", f.Label)
-			} else {
-				if v.Min != 0 && num < v.Min {
-					fieldErrors[f.ID] = fmt.Sprintf("'%s' must be ≥ %.2f", f.Label, v.Min)
-				}
-				if v.Max != 0 && num > v.Max {
-					fieldErrors[f.ID] = fmt.Sprintf("'%s' must be ≤ %.2f", f.Label, v.Max)
-				}
-			}
-		}
-
-		// ---------- Regex ----------
-		if v.Pattern != "" && val != "" {
-			re, err := regexp.Compile(v.Pattern)
-			if err == nil && !re.MatchString(val) {
-				fieldErrors[f.ID] = fmt.Sprintf("'%s' does not match expected format", f.Label)
-			}
-		}
-
-		// ---------- Date range ----------
-		if f.Type == "date" && val != "" {
-			dateVal, err := time.Parse("2006-01-02", val)
-			if err != nil {
-				fieldErrors[f.ID] = fmt.Sprintf("'%s' is not a valid date", f.Label)
-			} else {
-				if v.MinDate != "" {
-					minDate, _ := time.Parse("2006-01-02", v.MinDate)
-					if dateVal.Before(minDate) {
-						fieldErrors[f.ID] = fmt.Sprintf("'%s' must be after %s", f.Label, v.MinDate)
-					}
-				}
-				if v.MaxDate != "" {
-					maxDate, _ := time.Parse("2006-01-02", v.MaxDate)
-					if dateVal.After(maxDate) {
-						fieldErrors[f.ID] = fmt.Sprintf("'%s' must be before %s", f.Label, v.MaxDate)
-					}
-				}
-			}
-		}
-
-		// ---------- Cross-field numeric/date checks ----------
-		if v.GreaterThanField != "" {
-			otherVal, ok := values[v.GreaterThanField]
-			if ok && otherVal != "" && val != "" {
-				if f.Type == "number" {
-					numA, _ := strconv.ParseFloat(val, 64)
-					numB, _ := strconv.ParseFloat(otherVal, 64)
-					if numA <= numB {
-						fieldErrors[f.ID] = fmt.Sprintf("'%s' must be greater than '%s'", f.Label, v.GreaterThanField)
-					}
-				} else if f.Type == "date" {
-					dateA, _ := time.Parse("2006-01-02", val)
-					dateB, _ := time.Parse("2006-01-02", otherVal)
-					if !dateA.After(dateB) {
-						fieldErrors[f.ID] = fmt.Sprintf("'%s' must be after '%s'", f.Label, v.GreaterThanField)
-					}
-				}
-			}
-		}
-
-		if v.LessThanField != "" {
-			otherVal, ok := values[v.LessThanField]
-			if ok && otherVal != "" && val != "" {
-				if f.Type == "number" {
-					numA, _ := strconv.ParseFloat(val, 64)
-					numB, _ := strconv.ParseFloat(otherVal, 64)
-					if numA >= numB {
-						fieldErrors[f.ID] = fmt.Sprintf("'%s' must be less than '%s'", f.Label, v.LessThanField)
-					}
-				} else if f.Type == "date" {
-					dateA, _ := time.Parse("2006-01-02", val)
-					dateB, _ := time.Parse("2006-01-02", otherVal)
-					if !dateA.Before(dateB) {
-						fieldErrors[f.ID] = fmt.Sprintf("'%s' must be before '%s'", f.Label, v.LessThanField)
-					}
-				}
-			}
-		}
-
-		// ---------- Date-specific cross checks ----------
-		if v.BeforeField != "" {
-			otherVal, ok := values[v.BeforeField]
-			if ok && otherVal != "" && val != "" {
-				dateA, err1 := time.Parse("2006-01-02", val)
-				dateB, err2 := time.Parse("2006-01-02", otherVal)
-				if err1 == nil && err2 == nil && !dateA.Before(dateB) {
-					fieldErrors[f.ID] = fmt.Sprintf("'%s' must be before '%s'", f.Label, v.BeforeField)
-				}
-			}
-		}
-
-		if v.AfterField != "" {
-			otherVal, ok := values[v.AfterField]
-			if ok && otherVal != "" && val != "" {
-				dateA, err1 := time.Parse("2006-01-02", val)
-				dateB, err2 := time.Parse("2006-01-02", otherVal)
-				if err1 == nil && err2 == nil && !dateA.After(dateB) {
-					fieldErrors[f.ID] = fmt.Sprintf("'%s' must be after '%s'", f.Label, v.AfterField)
-				}
-			}
-		}
-
-		// ---------- Equal / Not Equal ----------
-		if v.EqualToField != "" {
-			otherVal := values[v.EqualToField]
-			if val != "" && otherVal != "" && val != otherVal {
-				fieldErrors[f.ID] = fmt.Sprintf("'%s' must equal '%s'", f.Label, v.EqualToField)
-			}
-		}
+	switch val := result.(type) {
+	case bool:
+		return val, nil
+	case float64:
+		return val != 0, nil
+	default:
+		return false, fmt.Errorf("unexpected formula result type: %T", val)
+	}
+}
 
-		if v.NotEqualToField != "" {
-			otherVal := values[v.NotEqualToField]
-			if val != "" && otherVal != "" && val == otherVal {
-				fieldErrors[f.ID] = fmt.Sprintf("'%s' must not equal '%s'", f.Label, v.NotEqualToField)
-			}
+// formulaFunctions are available in every formula, relevance and calculate expression.
+var formulaFunctions = map[string]govaluate.ExpressionFunction{
+	// selected(field, "choice") reports whether a select or select_multiple field has that choice
+	"selected": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("selected() expects 2 arguments, got %d", len(args))
 		}
-
-		// ---------- Formula-based validation ----------
-		if v.Formula != "" {
-			ok, err := evalFormula(v.Formula, values)
-			if err != nil {
-				fieldErrors[f.ID] = fmt.Sprintf("Invalid formula for '%s': %v", f.Label, err)
-			} else if !ok {
-				if v.ErrorMessage != "" {
-					fieldErrors[f.ID] = v.ErrorMessage
-				} else {
-					fieldErrors[f.ID] = fmt.Sprintf("Formula validation failed for '%s'", f.Label)
-				}
+		choice := formatResult(args[1])
+		for _, v := range splitList(formatResult(args[0])) {
+			if v == choice {
+				return true, nil
 			}
 		}
-	}
-
-	if len(fieldErrors) > 0 {
-		return fieldErrors, fmt.Errorf("one or more fields are invalid")
-	}
-
-	return nil, nil
+		return false, nil
+	},
+	// today() is the current date and now() the current date and time, as
+	// timestamps comparable with date and datetime fields
+	"today": func(args ...interface{}) (interface{}, error) {
+		now := time.Now()
+		return float64(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Unix()), nil
+	},
+	"now": func(args ...interface{}) (interface{}, error) {
+		now := time.Now()
+		return float64(time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC).Unix()), nil
+	},
 }
 
-// evalFormula safely evaluates a logical or arithmetic formula and returns whether it’s true.
-func evalFormula(expr string, values map[string]string) (bool, error) {
+// evalExpression evaluates an expression over the field values and returns the raw result.
+func evalExpression(expr string, values map[string]string) (interface{}, error) {
 	// Parse the formula expression
-	expression, err := govaluate.NewEvaluableExpression(expr)
+	expression, err := govaluate.NewEvaluableExpressionWithFunctions(expr, formulaFunctions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula syntax: %w", err)
+	}
+
+	// Evaluate safely
+	result, err := expression.Evaluate(formulaParameters(values))
 	if err != nil {
-		return false, fmt.Errorf("invalid formula syntax: %w", err)
+		return nil, fmt.Errorf("formula evaluation error: %w", err)
 	}
+	return result, nil
+}
 
-	// Build parameters for all known field values
+// formulaParameters converts field values into typed expression parameters.
+func formulaParameters(values map[string]string) map[string]interface{} {
 	parameters := make(map[string]interface{})
 	for id, value := range values {
 		if value == "" {
@@ -522,8 +1055,8 @@ func evalFormula(expr string, values map[string]string) (bool, error) {
 			continue
 		}
 
-		// Try date (parse as timestamp)
-		if t, err := time.Parse("2006-01-02", value); err == nil {
+		// Try date, time or datetime (parse as timestamp)
+		if t, ok := parseAnyTemporal(value); ok {
 			parameters[id] = float64(t.Unix())
 			continue
 		}
@@ -531,20 +1064,20 @@ func evalFormula(expr string, values map[string]string) (bool, error) {
 		// Fallback to string
 		parameters[id] = value
 	}
+	return parameters
+}
 
-	// Evaluate safely
-	result, err := expression.Evaluate(parameters)
-	if err != nil {
-		return false, fmt.Errorf("formula evaluation error: %w", err)
-	}
-
+// formatResult renders an expression result or a typed draft value as field text.
+func formatResult(result interface{}) string {
 	switch val := result.(type) {
-	case bool:
-		return val, nil
+	case nil:
+		return ""
 	case float64:
-		return val != 0, nil
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
 	default:
-		return false, fmt.Errorf("unexpected formula result type: %T", val)
+		return fmt.Sprintf("%v", val)
 	}
 }
 
@@ -0,0 +1,175 @@
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// GeoPoint is a WGS84 position; Accuracy is in metres and 0 when unknown.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64
+}
+
+// String renders the point as geopoint field text: "lat lon accuracy".
+func (p GeoPoint) String() string {
+	return fmt.Sprintf("%s %s %s",
+		strconv.FormatFloat(p.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(p.Longitude, 'f', -1, 64),
+		strconv.FormatFloat(p.Accuracy, 'f', -1, 64))
+}
+
+func (p GeoPoint) toMap() map[string]any {
+	return map[string]any{
+		"latitude":  p.Latitude,
+		"longitude": p.Longitude,
+		"accuracy":  p.Accuracy,
+	}
+}
+
+// parseGeoPoint reads "lat lon [accuracy]" and checks the coordinate ranges.
+func parseGeoPoint(raw string) (GeoPoint, error) {
+	var p GeoPoint
+	parts := strings.Fields(raw)
+	if len(parts) < 2 || len(parts) > 3 {
+		return p, fmt.Errorf("expected latitude and longitude")
+	}
+	nums := make([]float64, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return p, fmt.Errorf("%q is not a number", part)
+		}
+		nums[i] = n
+	}
+	p.Latitude, p.Longitude = nums[0], nums[1]
+	if len(nums) == 3 {
+		p.Accuracy = nums[2]
+	}
+	switch {
+	case p.Latitude < -90 || p.Latitude > 90:
+		return p, fmt.Errorf("latitude must be between -90 and 90")
+	case p.Longitude < -180 || p.Longitude > 180:
+		return p, fmt.Errorf("longitude must be between -180 and 180")
+	case p.Accuracy < 0:
+		return p, fmt.Errorf("accuracy cannot be negative")
+	}
+	return p, nil
+}
+
+// geoPointFromMap reads a point back from its payload object.
+func geoPointFromMap(m map[string]any) (GeoPoint, bool) {
+	lat, ok1 := m["latitude"].(float64)
+	lon, ok2 := m["longitude"].(float64)
+	if !ok1 || !ok2 {
+		return GeoPoint{}, false
+	}
+	acc, _ := m["accuracy"].(float64)
+	return GeoPoint{Latitude: lat, Longitude: lon, Accuracy: acc}, true
+}
+
+// LocationProvider supplies the device position to geopoint fields.
+type LocationProvider interface {
+	CurrentLocation() (GeoPoint, error)
+}
+
+// DefaultLocationProvider is called by the "Use current location" button of
+// geopoint fields. When nil, the button is hidden and only manual entry works.
+var DefaultLocationProvider LocationProvider
+
+// ErrLocationUnavailable is reported by devices without a position source.
+var ErrLocationUnavailable = errors.New("location unavailable")
+
+// NoLocationProvider is the provider of devices without GPS: it always
+// reports ErrLocationUnavailable, so the user knows to enter the point by hand.
+type NoLocationProvider struct{}
+
+func (NoLocationProvider) CurrentLocation() (GeoPoint, error) {
+	return GeoPoint{}, ErrLocationUnavailable
+}
+
+// FakeLocationProvider always reports the same point, for desktops and
+// development machines without GPS hardware.
+type FakeLocationProvider struct {
+	Point GeoPoint
+	Err   error
+}
+
+func (f FakeLocationProvider) CurrentLocation() (GeoPoint, error) {
+	return f.Point, f.Err
+}
+
+// geoPointInput is the geopoint field widget: latitude, longitude and
+// accuracy entries plus an optional auto-fill button.
+type geoPointInput struct {
+	lat, lon, acc *widget.Entry
+	content       fyne.CanvasObject
+}
+
+func newGeoPointInput(a fyne.App, provider LocationProvider, onChanged func()) *geoPointInput {
+	g := &geoPointInput{
+		lat: widget.NewEntry(),
+		lon: widget.NewEntry(),
+		acc: widget.NewEntry(),
+	}
+	g.lat.SetPlaceHolder("Latitude")
+	g.lon.SetPlaceHolder("Longitude")
+	g.acc.SetPlaceHolder("Accuracy (m)")
+	for _, e := range []*widget.Entry{g.lat, g.lon, g.acc} {
+		e.OnChanged = func(string) { onChanged() }
+	}
+
+	entries := container.NewGridWithColumns(3, g.lat, g.lon, g.acc)
+	if provider == nil {
+		g.content = entries
+		return g
+	}
+
+	var locate *widget.Button
+	locate = widget.NewButtonWithIcon("Use current location", theme.SearchIcon(), func() {
+		locate.Disable()
+		go func() {
+			p, err := provider.CurrentLocation()
+			fyne.Do(func() {
+				locate.Enable()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("Could not get location: %v", err), a.Driver().AllWindows()[0])
+					return
+				}
+				g.SetValue(p.String())
+			})
+		}()
+	})
+	g.content = container.NewVBox(entries, locate)
+	return g
+}
+
+// Value returns "lat lon accuracy", or "" when nothing was entered.
+func (g *geoPointInput) Value() string {
+	parts := []string{
+		strings.TrimSpace(g.lat.Text),
+		strings.TrimSpace(g.lon.Text),
+		strings.TrimSpace(g.acc.Text),
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+func (g *geoPointInput) SetValue(raw string) {
+	parts := strings.Fields(raw)
+	for i, e := range []*widget.Entry{g.lat, g.lon, g.acc} {
+		if i < len(parts) {
+			e.SetText(parts[i])
+		} else {
+			e.SetText("")
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package forms
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"fyne.io/fyne/v2"
+)
+
+// Text is a user-facing string of a form definition: a label, title, hint,
+// option or message. In forms.json it is either a plain string, shown in
+// every language, or translations keyed by locale:
+//
+//	"label": {"en": "District", "lg": "Disitulikiti", "sw": "Wilaya"}
+type Text map[string]string
+
+// FallbackLocale is the default locale of forms whose bundle names none.
+const FallbackLocale = "en"
+
+// Locale is the language texts are shown in: the translation for Name, or
+// for Default, the form's default locale, where a text has none for Name.
+type Locale struct {
+	Name    string
+	Default string
+}
+
+// Locale returns the locale to show the form in: name, or the form's default
+// locale if name is empty, falling back to the default locale.
+func (d FormDefinition) Locale(name string) Locale {
+	def := cmp.Or(d.DefaultLocale, FallbackLocale)
+	return Locale{Name: cmp.Or(name, def), Default: def}
+}
+
+// localeKey is the preference holding the locale forms are shown in.
+const localeKey = "locale"
+
+// localeNames are the names the language switcher shows for known locales.
+var localeNames = map[string]string{
+	"en": "English",
+	"lg": "Luganda",
+	"sw": "Kiswahili",
+	"fr": "Français",
+}
+
+// Plain returns a text shown the same in every language.
+func Plain(s string) Text {
+	if s == "" {
+		return nil
+	}
+	return Text{"": s}
+}
+
+// In returns the text in a locale, falling back to its default locale, then
+// to the untranslated text, then to the first translation by locale.
+func (t Text) In(l Locale) string {
+	if s, ok := t[l.Name]; ok {
+		return s
+	}
+	if s, ok := t[l.Default]; ok {
+		return s
+	}
+	if s, ok := t[""]; ok {
+		return s
+	}
+	locales := make([]string, 0, len(t))
+	for l := range t {
+		locales = append(locales, l)
+	}
+	if len(locales) == 0 {
+		return ""
+	}
+	sort.Strings(locales)
+	return t[locales[0]]
+}
+
+// String returns the text in FallbackLocale, for places that show a text
+// outside of any form, such as logs.
+func (t Text) String() string {
+	return t.In(Locale{Name: FallbackLocale})
+}
+
+// Translated reports whether the text has translations rather than one
+// plain string.
+func (t Text) Translated() bool {
+	_, plain := t[""]
+	return len(t) > 1 || (len(t) == 1 && !plain)
+}
+
+// MarshalJSON writes untranslated texts as plain strings.
+func (t Text) MarshalJSON() ([]byte, error) {
+	if !t.Translated() {
+		return json.Marshal(t[""])
+	}
+	return json.Marshal(map[string]string(t))
+}
+
+// UnmarshalJSON reads a plain string or an object of translations.
+func (t *Text) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = Plain(s)
+		return nil
+	}
+	var translations map[string]string
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return fmt.Errorf("a text must be a string or translations by locale: %s", data)
+	}
+	*t = translations
+	return nil
+}
+
+// MarshalJSON writes options without a label as plain strings.
+func (o Option) MarshalJSON() ([]byte, error) {
+	if o.Label == nil {
+		return json.Marshal(o.Value)
+	}
+	type option Option // without the methods, so this isn't called again
+	return json.Marshal(option(o))
+}
+
+// UnmarshalJSON reads a plain string or an object with a value and a label.
+func (o *Option) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*o = Option{Value: s}
+		return nil
+	}
+	type option Option
+	var v option
+	if err := json.Unmarshal(data, &v); err != nil || v.Value == "" {
+		return fmt.Errorf("an option must be a string or an object with a value and a label: %s", data)
+	}
+	*o = Option(v)
+	return nil
+}
+
+// JoinText joins texts locale by locale, e.g. nested group titles. A part
+// without a translation for a locale contributes its fallback.
+func JoinText(parts []Text, sep string) Text {
+	locales := make(map[string]bool)
+	for _, p := range parts {
+		for l := range p {
+			locales[l] = true
+		}
+	}
+	joined := Text{}
+	for l := range locales {
+		s := ""
+		for i, p := range parts {
+			if i > 0 {
+				s += sep
+			}
+			s += p.In(Locale{Name: l, Default: FallbackLocale})
+		}
+		joined[l] = s
+	}
+	if len(joined) == 0 {
+		return nil
+	}
+	return joined
+}
+
+// CurrentLocale returns the locale chosen with SetLocale, or "" if none was
+// chosen; FormDefinition.Locale then uses the form's default.
+func CurrentLocale(a fyne.App) string {
+	return a.Preferences().String(localeKey)
+}
+
+// SetLocale changes the locale forms are shown in; screens built afterwards use it.
+func SetLocale(a fyne.App, locale string) {
+	a.Preferences().SetString(localeKey, locale)
+}
+
+// LocaleName returns the name of a locale for the language switcher.
+func LocaleName(locale string) string {
+	if name, ok := localeNames[locale]; ok {
+		return name
+	}
+	return locale
+}
+
+// Locales returns the locales the forms have translations for, with their
+// default locales first. Forms without translations have none.
+func Locales(defs map[string]FormDefinition) []string {
+	found := make(map[string]bool)
+	defaults := make(map[string]bool)
+	add := func(t Text) {
+		for l := range t {
+			if l != "" {
+				found[l] = true
+			}
+		}
+	}
+	for _, def := range defs {
+		defaults[def.Locale("").Default] = true
+		add(def.Meta.Name)
+		add(def.Meta.Description)
+		for _, sec := range def.Sections {
+			add(sec.Title)
+			for _, f := range sec.Fields {
+				for _, t := range fieldTexts(f) {
+					add(t)
+				}
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	var first, rest []string
+	for l := range defaults {
+		first = append(first, l)
+	}
+	for l := range found {
+		if !defaults[l] {
+			rest = append(rest, l)
+		}
+	}
+	sort.Strings(first)
+	sort.Strings(rest)
+	return append(first, rest...)
+}
+
+// fieldTexts returns every translatable text of a field.
+func fieldTexts(f Field) []Text {
+	texts := []Text{f.Label, f.Hint, f.Placeholder, f.Validation.ErrorMessage}
+	for _, r := range f.Validation.Rules {
+		texts = append(texts, r.Message)
+	}
+	for _, c := range f.Choices {
+		texts = append(texts, c.Label)
+	}
+	for _, opt := range f.Options {
+		texts = append(texts, opt.Label)
+	}
+	return texts
+}
+
+// optionLabels maps the option values of a select field onto the text its
+// widget shows in the chosen locale. Values without a label are shown as
+// they are.
+type optionLabels map[string]string
+
+func newOptionLabels(f Field, locale Locale) optionLabels {
+	labels := make(optionLabels, len(f.Options))
+	for _, opt := range f.Options {
+		if opt.Label != nil {
+			labels[opt.Value] = opt.Label.In(locale)
+		}
+	}
+	for _, c := range f.Choices {
+		if c.Label != nil {
+			labels[c.Value] = c.Label.In(locale)
+		}
+	}
+	return labels
+}
+
+func (o optionLabels) label(value string) string {
+	if label, ok := o[value]; ok {
+		return label
+	}
+	return value
+}
+
+func (o optionLabels) value(label string) string {
+	for value, l := range o {
+		if l == label {
+			return value
+		}
+	}
+	return label
+}
+
+func (o optionLabels) labels(values []string) []string {
+	labels := make([]string, len(values))
+	for i, v := range values {
+		labels[i] = o.label(v)
+	}
+	return labels
+}
+
+func (o optionLabels) values(labels []string) []string {
+	values := make([]string, len(labels))
+	for i, l := range labels {
+		values[i] = o.value(l)
+	}
+	return values
+}
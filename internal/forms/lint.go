@@ -0,0 +1,251 @@
+package forms
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Knetic/govaluate"
+)
+
+// fieldTypes are the field types BuildForm knows how to render.
+var fieldTypes = map[string]bool{
+	"text": true, "multiline": true,
+	"number": true, "integer": true, "decimal": true,
+	"select": true, "select_multiple": true, "boolean": true,
+	"date": true, "time": true, "datetime": true, "period": true,
+	"geopoint": true, "attachment": true, "signature": true,
+}
+
+// periodTypes are the accepted values of Field.PeriodType.
+var periodTypes = map[string]bool{"weekly": true, "epiweek": true, "monthly": true, "quarterly": true}
+
+// LintIssue is a problem found in a form bundle, located by a JSON path such
+// as $.forms.CASES.sections[0].fields[2].validation.pattern.
+type LintIssue struct {
+	Path    string
+	Message string
+	Warning bool // warnings don't stop the bundle from working
+}
+
+func (i LintIssue) String() string {
+	level := "error"
+	if i.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Path, level, i.Message)
+}
+
+// LintBundle checks a form bundle for mistakes that would otherwise only show
+// up on devices, such as duplicate field IDs, references to missing fields,
+// broken patterns and expressions, and unknown field types. Forms are checked
+// in name order so the report is stable.
+func LintBundle(b FormBundle) []LintIssue {
+	var issues []LintIssue
+	report := func(path, format string, args ...any) {
+		issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(path, format string, args ...any) {
+		issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+	}
+
+	if len(b.Forms) == 0 {
+		report("$.forms", "bundle has no forms")
+	}
+	listed := make(map[string]bool)
+	for i, name := range b.FormOrder {
+		path := fmt.Sprintf("$.form_order[%d]", i)
+		if _, ok := b.Forms[name]; !ok {
+			report(path, "no form named %q", name)
+		}
+		if listed[name] {
+			warn(path, "%q is listed more than once", name)
+		}
+		listed[name] = true
+	}
+
+	names := make([]string, 0, len(b.Forms))
+	for name := range b.Forms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		formPath := "$.forms." + name
+		if len(b.FormOrder) > 0 && !listed[name] {
+			warn(formPath, "not listed in form_order, so it is hidden from the dashboard")
+		}
+		issues = append(issues, lintForm(b, formPath, b.Forms[name])...)
+	}
+	return issues
+}
+
+// lintForm checks one form. Field IDs share a single namespace across the
+// form, repeat sections included, since expressions in a repeat instance
+// see the top-level values too.
+func lintForm(b FormBundle, formPath string, def FormDefinition) []LintIssue {
+	var issues []LintIssue
+	report := func(path, format string, args ...any) {
+		issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(path, format string, args ...any) {
+		issues = append(issues, LintIssue{Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+	}
+
+	ids := make(map[string]string) // field ID -> path of its first use
+	for si, sec := range def.Sections {
+		for fi, f := range sec.Fields {
+			path := fmt.Sprintf("%s.sections[%d].fields[%d]", formPath, si, fi)
+			switch first, dup := ids[f.ID]; {
+			case f.ID == "":
+				report(path+".id", "field has no ID")
+			case dup:
+				report(path+".id", "duplicate field ID %q, first used at %s", f.ID, first)
+			default:
+				ids[f.ID] = path
+			}
+		}
+	}
+
+	// checkExpr reports expressions that don't parse or use unknown fields
+	checkExpr := func(path, expr string) {
+		if expr == "" {
+			return
+		}
+		parsed, err := govaluate.NewEvaluableExpressionWithFunctions(expr, formulaFunctions)
+		if err != nil {
+			report(path, "invalid expression %q: %v", expr, err)
+			return
+		}
+		for _, v := range parsed.Vars() {
+			if _, ok := ids[v]; !ok {
+				report(path, "expression %q refers to unknown field %q", expr, v)
+			}
+		}
+	}
+	checkRef := func(path, id string) {
+		if _, ok := ids[id]; id != "" && !ok {
+			report(path, "refers to unknown field %q", id)
+		}
+	}
+	checkBound := func(path, fieldType, bound string) {
+		if _, ok := resolveBound(fieldType, bound, time.Now(), false); bound != "" && !ok {
+			report(path, "cannot read %q as a %s bound", bound, fieldType)
+		}
+	}
+	// translated texts need the fallback, or another translation shows instead
+	fallback := cmp.Or(def.DefaultLocale, b.DefaultLocale, FallbackLocale)
+	checkText := func(path string, t Text) {
+		if _, ok := t[fallback]; t.Translated() && !ok {
+			if _, ok := t[""]; !ok {
+				warn(path, "no %q translation, so another one is shown where a translation is missing", fallback)
+			}
+		}
+	}
+
+	checkText(formPath+".meta.name", def.Meta.Name)
+	checkText(formPath+".meta.description", def.Meta.Description)
+
+	if len(def.Sections) == 0 {
+		report(formPath+".sections", "form has no sections")
+	}
+	for si, sec := range def.Sections {
+		secPath := fmt.Sprintf("%s.sections[%d]", formPath, si)
+		checkText(secPath+".title", sec.Title)
+		if sec.Repeat && sec.ID == "" && sec.Title.Translated() {
+			report(secPath+".id", "a repeat section with a translated title needs an id, its payload key")
+		}
+		checkExpr(secPath+".relevant", sec.Relevant)
+		if sec.MaxRepeat > 0 && sec.MaxRepeat < sec.MinRepeat {
+			report(secPath+".maxRepeat", "maxRepeat %d is below minRepeat %d", sec.MaxRepeat, sec.MinRepeat)
+		}
+
+		for fi, f := range sec.Fields {
+			path := fmt.Sprintf("%s.fields[%d]", secPath, fi)
+			v := f.Validation
+
+			if !fieldTypes[f.Type] && f.Calculate == "" {
+				report(path+".type", "unknown field type %q", f.Type)
+			}
+			if f.Type == "period" && f.PeriodType != "" && !periodTypes[f.PeriodType] {
+				report(path+".periodType", "unknown period type %q", f.PeriodType)
+			}
+			if f.ChoiceList != "" {
+				if _, ok := b.Choices[f.ChoiceList]; !ok {
+					report(path+".choiceList", "no choice list named %q", f.ChoiceList)
+				}
+			}
+			checkText(path+".label", f.Label)
+			checkText(path+".hint", f.Hint)
+			checkText(path+".placeholder", f.Placeholder)
+			checkText(path+".validation.errorMessage", v.ErrorMessage)
+			for oi, opt := range f.Options {
+				checkText(fmt.Sprintf("%s.options[%d].label", path, oi), opt.Label)
+			}
+			checkRef(path+".choiceFilter", f.ChoiceFilter)
+			checkExpr(path+".relevant", f.Relevant)
+			checkExpr(path+".calculate", f.Calculate)
+			if defaultCall.MatchString(f.Default) {
+				checkExpr(path+".default", f.Default)
+			}
+
+			// checkRule checks the expressions, references and bounds of a
+			// rule, or of the rules set directly on Validation
+			checkRule := func(rPath string, r Rule) {
+				if r.Pattern != "" {
+					if _, err := regexp.Compile(r.Pattern); err != nil {
+						report(rPath+".pattern", "invalid pattern: %v", err)
+					}
+				}
+				checkExpr(rPath+".formula", r.Formula)
+				checkRef(rPath+".greaterThanField", r.GreaterThanField)
+				checkRef(rPath+".lessThanField", r.LessThanField)
+				checkRef(rPath+".beforeField", r.BeforeField)
+				checkRef(rPath+".afterField", r.AfterField)
+				checkRef(rPath+".equalToField", r.EqualToField)
+				checkRef(rPath+".notEqualToField", r.NotEqualToField)
+				if isTemporal(f.Type) {
+					checkBound(rPath+".minDate", f.Type, r.MinDate)
+					checkBound(rPath+".maxDate", f.Type, r.MaxDate)
+				}
+			}
+
+			vPath := path + ".validation"
+			checkRule(vPath, Rule{
+				Pattern: v.Pattern, Formula: v.Formula,
+				GreaterThanField: v.GreaterThanField, LessThanField: v.LessThanField,
+				BeforeField: v.BeforeField, AfterField: v.AfterField,
+				EqualToField: v.EqualToField, NotEqualToField: v.NotEqualToField,
+				MinDate: v.MinDate, MaxDate: v.MaxDate,
+			})
+			if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+				report(vPath+".max", "max %v is below min %v", *v.Max, *v.Min)
+			}
+			if v.MaxLength > 0 && v.MinLength > v.MaxLength {
+				report(vPath+".maxLength", "maxLength %d is below minLength %d", v.MaxLength, v.MinLength)
+			}
+			if v.MaxSelected > 0 && v.MinSelected > v.MaxSelected {
+				report(vPath+".maxSelected", "maxSelected %d is below minSelected %d", v.MaxSelected, v.MinSelected)
+			}
+			for wi, rule := range v.Warnings {
+				if !warningRules[rule] {
+					report(fmt.Sprintf("%s.warnings[%d]", vPath, wi), "unknown rule %q", rule)
+				}
+			}
+			for ri, r := range v.Rules {
+				rPath := fmt.Sprintf("%s.rules[%d]", vPath, ri)
+				switch checks := r.checks(); {
+				case len(checks) == 0:
+					report(rPath, "rule has no check")
+				case len(checks) > 1:
+					warn(rPath, "rule has several checks (%s); give each its own rule so each can have its own message", strings.Join(checks, ", "))
+				}
+				checkRule(rPath, r)
+				checkText(rPath+".message", r.Message)
+			}
+		}
+	}
+	return issues
+}
@@ -1,53 +1,162 @@
 package forms
 
 type Validation struct {
-	Required         bool    `json:"required"`
-	MinLength        int     `json:"minLength"`
-	MaxLength        int     `json:"maxLength"`
-	Min              float64 `json:"min"`
-	Max              float64 `json:"max"`
-	Pattern          string  `json:"pattern"`
-	MinDate          string  `json:"minDate"`
-	MaxDate          string  `json:"maxDate"`
-	GreaterThanField string  `json:"greaterThanField"` // A > B
-	LessThanField    string  `json:"lessThanField"`    // A < B
-	BeforeField      string  `json:"beforeField"`      // A before B (dates)
-	AfterField       string  `json:"afterField"`
-	EqualToField     string  `json:"equalToField"`    // equal
-	NotEqualToField  string  `json:"notEqualToField"` // not equal
-	Formula          string  `json:"formula"`         // e.g., "A + B == C"
-	ErrorMessage     string  `json:"errorMessage"`    // optional custom message
+	Required         bool     `json:"required"`
+	MinLength        int      `json:"minLength"`
+	MaxLength        int      `json:"maxLength"`
+	Min              *float64 `json:"min,omitempty"` // nil = no bound; 0 is a bound
+	Max              *float64 `json:"max,omitempty"`
+	Pattern          string   `json:"pattern"`
+	MinDate          string   `json:"minDate"` // fixed, or relative: "today", "-7d", "start_of_week-4w"
+	MaxDate          string   `json:"maxDate"`
+	GreaterThanField string   `json:"greaterThanField"` // A > B
+	LessThanField    string   `json:"lessThanField"`    // A < B
+	BeforeField      string   `json:"beforeField"`      // A before B (dates and times)
+	AfterField       string   `json:"afterField"`
+	EqualToField     string   `json:"equalToField"`    // equal
+	NotEqualToField  string   `json:"notEqualToField"` // not equal
+	MinSelected      int      `json:"minSelected"`     // select_multiple only
+	MaxSelected      int      `json:"maxSelected"`     // select_multiple only
+	Formula          string   `json:"formula"`         // e.g., "A + B == C" or selected(symptoms, "fever")
+	ErrorMessage     Text     `json:"errorMessage"`    // optional custom message
+
+	// Attachment limits
+	MaxSize      int64    `json:"maxSize"`      // bytes
+	AllowedTypes []string `json:"allowedTypes"` // MIME types, e.g. "image/*"
+
+	// Number format; "number" fields are non-negative decimals unless allowed
+	AllowNegative bool `json:"allowNegative"`
+	DecimalPlaces int  `json:"decimalPlaces"` // 0 = unlimited
+
+	// Period limits: periods that haven't started are rejected unless allowed
+	AllowFuture bool `json:"allowFuture"`
+
+	// Warnings lists rules, by JSON name, that only warn, e.g. ["max"] for a
+	// plausibility check: the value is accepted once the user confirms it
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Rules are further checks in the v2 constraint model, applied in order
+	// after the ones above, each with its own message and level
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule is one check of the v2 constraint model. Checks are named as in
+// Validation, and a rule sets just one, so it can have its own message and
+// be a warning on its own. Every rule that fails is reported:
+//
+//	"validation": {"rules": [
+//	  {"required": true},
+//	  {"min": 0, "message": "Cases can't be negative"},
+//	  {"max": 50, "warning": true, "message": "More than 50 cases in a week?"}
+//	]}
+type Rule struct {
+	Required         bool     `json:"required,omitempty"`
+	MinLength        int      `json:"minLength,omitempty"`
+	MaxLength        int      `json:"maxLength,omitempty"`
+	Min              *float64 `json:"min,omitempty"`
+	Max              *float64 `json:"max,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"`
+	MinDate          string   `json:"minDate,omitempty"`
+	MaxDate          string   `json:"maxDate,omitempty"`
+	GreaterThanField string   `json:"greaterThanField,omitempty"`
+	LessThanField    string   `json:"lessThanField,omitempty"`
+	BeforeField      string   `json:"beforeField,omitempty"`
+	AfterField       string   `json:"afterField,omitempty"`
+	EqualToField     string   `json:"equalToField,omitempty"`
+	NotEqualToField  string   `json:"notEqualToField,omitempty"`
+	MinSelected      int      `json:"minSelected,omitempty"`
+	MaxSelected      int      `json:"maxSelected,omitempty"`
+	Formula          string   `json:"formula,omitempty"`
+	MaxSize          int64    `json:"maxSize,omitempty"`
+	AllowedTypes     []string `json:"allowedTypes,omitempty"`
+
+	Message Text `json:"message,omitempty"` // replaces the default message
+	Warning bool `json:"warning,omitempty"` // only warns, like Validation.Warnings
 }
 
 type Field struct {
 	ID         string     `json:"id"`
-	Label      string     `json:"label"`
+	Label      Text       `json:"label"`
 	Type       string     `json:"type"`
-	Options    []string   `json:"options"`
+	Options    []Option   `json:"options"`
 	Validation Validation `json:"validation"`
+	Relevant   string     `json:"relevant,omitempty"`  // e.g., "other_flag == true"; field hidden when false
+	Calculate  string     `json:"calculate,omitempty"` // e.g., "A + B + C"; read-only, recomputed on change
+	// ChoiceList names a bundle-level choice list used instead of Options;
+	// it is copied into Choices when the bundle is loaded.
+	ChoiceList string   `json:"choiceList,omitempty"`
+	Choices    []Choice `json:"choices,omitempty"`
+	// ChoiceFilter is the ID of a parent field: only choices whose Parents
+	// entry for it matches the parent's current value are offered.
+	ChoiceFilter string `json:"choiceFilter,omitempty"`
+	// PeriodType is "weekly" (ISO), "epiweek", "monthly" or "quarterly" for period fields
+	PeriodType string `json:"periodType,omitempty"`
+	// Hint is shown under the label; Placeholder inside inputs that have one
+	Hint        Text `json:"hint,omitempty"`
+	Placeholder Text `json:"placeholder,omitempty"`
+	// Default prefills a new form (not a draft); a value containing a call,
+	// e.g. "today()", is evaluated as an expression.
+	Default string `json:"default,omitempty"`
+	// Sticky fields start from the user's last submitted value, e.g. their facility
+	Sticky bool `json:"sticky,omitempty"`
+}
+
+// Choice is one option of a cascading choice list, e.g.
+// {"value": "Bobi", "parents": {"district": "Gulu"}}. The value is shown
+// unless the choice has a label.
+type Choice struct {
+	Value   string            `json:"value"`
+	Label   Text              `json:"label,omitempty"`
+	Parents map[string]string `json:"parents,omitempty"`
+}
+
+// Option is one answer of a select field. The value is submitted and stored
+// whichever language the form is shown in; the label, if set, is shown
+// instead, e.g. {"value": "male", "label": {"en": "Male", "lg": "Musajja"}}.
+// In forms.json an option without a label is a plain string.
+type Option struct {
+	Value string `json:"value"`
+	Label Text   `json:"label,omitempty"`
 }
 
 type Section struct {
-	Title   string  `json:"title"`
+	ID      string  `json:"id,omitempty"` // payload key for repeat sections, see Key
+	Title   Text    `json:"title"`
 	Layout  string  `json:"layout"`  // "stack" or "grid"
 	Columns int     `json:"columns"` // optional, for grid layout
 	Fields  []Field `json:"fields"`
+	// Relevant hides the whole section while the expression is false
+	Relevant string `json:"relevant,omitempty"`
+	// Repeat sections are filled in several times, e.g. one per patient
+	Repeat    bool `json:"repeat,omitempty"`
+	MinRepeat int  `json:"minRepeat,omitempty"`
+	MaxRepeat int  `json:"maxRepeat,omitempty"` // 0 = unlimited
 }
 
 type FormDefinition struct {
 	Meta     FormMeta  `json:"meta"`
 	Sections []Section `json:"sections"`
+	// Layout is "tabs" (the default) or "wizard": one section per page with
+	// Next/Previous buttons and a review page before submitting
+	Layout string `json:"layout,omitempty"`
+	// DefaultLocale is shown where a text lacks the chosen translation; the
+	// bundle's when empty
+	DefaultLocale string `json:"defaultLocale,omitempty"`
 }
 
 type FormMeta struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        Text   `json:"name"`
+	Description Text   `json:"description"`
 	Icon        string `json:"icon"`
 }
 
 type FormBundle struct {
+	Schema      string                    `json:"$schema,omitempty"` // editor hint, e.g. "./forms.schema.json"
 	Version     string                    `json:"version"`
 	LastUpdated string                    `json:"lastUpdated,omitempty"`
 	Forms       map[string]FormDefinition `json:"forms"`
 	FormOrder   []string                  `json:"form_order,omitempty"`
+	Choices     map[string][]Choice       `json:"choices,omitempty"` // shared choice lists by name
+	// DefaultLocale is shown where a text lacks the chosen translation
+	DefaultLocale string `json:"defaultLocale,omitempty"`
 }
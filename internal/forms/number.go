@@ -0,0 +1,142 @@
+package forms
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/lang"
+)
+
+// isNumeric reports whether a field type holds a number. "number" is the
+// original, untyped numeric field and behaves like "decimal".
+func isNumeric(fieldType string) bool {
+	switch fieldType {
+	case "number", "integer", "decimal":
+		return true
+	}
+	return false
+}
+
+// commaDecimalLanguages write decimals with a comma, e.g. "3,5".
+var commaDecimalLanguages = map[string]bool{
+	"de": true, "fr": true, "es": true, "it": true, "pt": true, "nl": true,
+	"ru": true, "sv": true, "da": true, "fi": true, "nb": true, "pl": true,
+	"cs": true, "tr": true, "id": true, "vi": true, "uk": true, "ro": true,
+}
+
+// decimalSeparator is the separator users type, from the system locale.
+// Values are always stored and sent with ".".
+func decimalSeparator() rune {
+	tag := strings.ToLower(lang.SystemLocale().String())
+	base, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	if commaDecimalLanguages[base] {
+		return ','
+	}
+	return '.'
+}
+
+// cleanNumber filters typed text down to an optional leading minus, digits
+// and, for decimals, a single separator followed by at most places digits.
+// A "." typed in a comma locale is turned into the local separator.
+func cleanNumber(text string, integer, negative bool, places int, sep rune) string {
+	var b strings.Builder
+	seenSep := false
+	decimals := 0
+	for _, r := range text {
+		switch {
+		case r == '-':
+			if negative && b.Len() == 0 {
+				b.WriteRune(r)
+			}
+		case r >= '0' && r <= '9':
+			if seenSep {
+				if places > 0 && decimals >= places {
+					continue
+				}
+				decimals++
+			}
+			b.WriteRune(r)
+		case r == sep || r == '.':
+			if !integer && !seenSep {
+				seenSep = true
+				b.WriteRune(sep)
+			}
+		}
+	}
+	return b.String()
+}
+
+// canonicalNumber turns filtered text into a parseable value with "." as
+// separator, completing half-typed input such as "-", "3," or ",5".
+func canonicalNumber(text string, sep rune) string {
+	s := strings.ReplaceAll(text, string(sep), ".")
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	s = strings.TrimSuffix(s, ".")
+	if s == "" {
+		return ""
+	}
+	if strings.HasPrefix(s, ".") {
+		s = "0" + s
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// numberInput is the entry for number, integer and decimal fields. Its
+// filter never lets through text that Value can't turn into a number.
+type numberInput struct {
+	entry *focusEntry
+	sep   rune
+}
+
+func newNumberInput(f Field, locale Locale, onChanged func()) *numberInput {
+	in := &numberInput{entry: newFocusEntry(), sep: decimalSeparator()}
+	integer := f.Type == "integer"
+	if integer {
+		in.entry.SetPlaceHolder("Enter whole number...")
+	} else {
+		in.entry.SetPlaceHolder("Enter number...")
+	}
+	if placeholder := f.Placeholder.In(locale); placeholder != "" {
+		in.entry.SetPlaceHolder(placeholder)
+	}
+
+	v := f.Validation
+	in.entry.OnChanged = func(text string) {
+		clean := cleanNumber(text, integer, v.AllowNegative, v.DecimalPlaces, in.sep)
+		if maxLen := v.limits().MaxLength; maxLen > 0 && len(clean) > maxLen {
+			clean = clean[:maxLen]
+		}
+		if clean != text {
+			in.entry.SetText(clean) // re-enters with the clean text
+			return
+		}
+		onChanged()
+	}
+	return in
+}
+
+func (in *numberInput) content() fyne.CanvasObject {
+	return in.entry
+}
+
+// Value returns the number with "." as separator, or "".
+func (in *numberInput) Value() string {
+	return canonicalNumber(in.entry.Text, in.sep)
+}
+
+// SetValue shows a stored number with the local separator.
+func (in *numberInput) SetValue(raw string) {
+	in.entry.SetText(strings.ReplaceAll(raw, ".", string(in.sep)))
+}
+
+// decimalPlaces counts the digits after the separator of a canonical number.
+func decimalPlaces(raw string) int {
+	if _, frac, ok := strings.Cut(raw, "."); ok {
+		return len(frac)
+	}
+	return 0
+}
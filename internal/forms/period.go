@@ -0,0 +1,216 @@
+package forms
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// period is a reporting period. IDs follow the DHIS2 conventions:
+// ISO weeks "2025W42", epi weeks (Sunday to Saturday) "2025SunW42",
+// months "202510" and quarters "2025Q4".
+type period struct {
+	kind  string // "weekly", "epiweek", "monthly" or "quarterly"
+	start time.Time
+	end   time.Time // last day, inclusive
+}
+
+var (
+	weeklyID    = regexp.MustCompile(`^(\d{4})W(\d{1,2})$`)
+	epiWeeklyID = regexp.MustCompile(`^(\d{4})SunW(\d{1,2})$`)
+	monthlyID   = regexp.MustCompile(`^(\d{4})(\d{2})$`)
+	quarterlyID = regexp.MustCompile(`^(\d{4})Q([1-4])$`)
+)
+
+// weekOneStart returns the first day of week 1: the week, starting on the
+// given weekday, that contains January 4th (i.e. has most of its days in the year).
+func weekOneStart(year int, first time.Weekday) time.Time {
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) - int(first) + 7) % 7
+	return jan4.AddDate(0, 0, -offset)
+}
+
+// weekOf returns the week-numbering year and week of a day.
+func weekOf(day time.Time, first time.Weekday) (int, int) {
+	year := day.Year()
+	if next := weekOneStart(year+1, first); !day.Before(next) {
+		return year + 1, 1
+	}
+	start := weekOneStart(year, first)
+	if day.Before(start) {
+		year--
+		start = weekOneStart(year, first)
+	}
+	return year, int(day.Sub(start).Hours()/24)/7 + 1
+}
+
+func weekStartDay(kind string) time.Weekday {
+	if kind == "epiweek" {
+		return time.Sunday
+	}
+	return time.Monday
+}
+
+// periodOf returns the period of the given type that contains t.
+func periodOf(kind string, t time.Time) period {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	p := period{kind: kind}
+	switch kind {
+	case "monthly":
+		p.start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		p.end = p.start.AddDate(0, 1, -1)
+	case "quarterly":
+		q := (int(day.Month()) - 1) / 3
+		p.start = time.Date(day.Year(), time.Month(q*3+1), 1, 0, 0, 0, 0, time.UTC)
+		p.end = p.start.AddDate(0, 3, -1)
+	default: // weekly, epiweek
+		if kind != "epiweek" {
+			p.kind = "weekly"
+		}
+		offset := (int(day.Weekday()) - int(weekStartDay(p.kind)) + 7) % 7
+		p.start = day.AddDate(0, 0, -offset)
+		p.end = p.start.AddDate(0, 0, 6)
+	}
+	return p
+}
+
+// parsePeriod reads a period ID, working out its type from the format.
+func parsePeriod(id string) (period, error) {
+	id = strings.TrimSpace(id)
+	week := func(m []string, kind string) (period, error) {
+		year, _ := strconv.Atoi(m[1])
+		w, _ := strconv.Atoi(m[2])
+		start := weekOneStart(year, weekStartDay(kind)).AddDate(0, 0, 7*(w-1))
+		if y, n := weekOf(start, weekStartDay(kind)); w < 1 || y != year || n != w {
+			return period{}, fmt.Errorf("%q is not a valid week", id)
+		}
+		return periodOf(kind, start), nil
+	}
+	if m := weeklyID.FindStringSubmatch(id); m != nil {
+		return week(m, "weekly")
+	}
+	if m := epiWeeklyID.FindStringSubmatch(id); m != nil {
+		return week(m, "epiweek")
+	}
+	if m := monthlyID.FindStringSubmatch(id); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return period{}, fmt.Errorf("%q is not a valid month", id)
+		}
+		return periodOf("monthly", time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+	if m := quarterlyID.FindStringSubmatch(id); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		q, _ := strconv.Atoi(m[2])
+		return periodOf("quarterly", time.Date(year, time.Month(q*3-2), 1, 0, 0, 0, 0, time.UTC)), nil
+	}
+	return period{}, fmt.Errorf("%q is not a valid period", id)
+}
+
+// ID returns the canonical period ID.
+func (p period) ID() string {
+	switch p.kind {
+	case "monthly":
+		return p.start.Format("200601")
+	case "quarterly":
+		return fmt.Sprintf("%dQ%d", p.start.Year(), (int(p.start.Month())-1)/3+1)
+	case "epiweek":
+		y, w := weekOf(p.start, time.Sunday)
+		return fmt.Sprintf("%dSunW%d", y, w)
+	default:
+		y, w := weekOf(p.start, time.Monday)
+		return fmt.Sprintf("%dW%d", y, w)
+	}
+}
+
+// Label is what the picker shows: the ID followed by the dates it covers.
+func (p period) Label() string {
+	switch p.kind {
+	case "monthly":
+		return p.ID() + " · " + p.start.Format("January 2006")
+	case "quarterly":
+		return p.ID() + " · " + p.start.Format("Jan") + " – " + p.end.Format("Jan 2006")
+	default:
+		return p.ID() + " · " + p.start.Format("2 Jan") + " – " + p.end.Format("2 Jan 2006")
+	}
+}
+
+// shift moves n periods forward (or back when negative).
+func (p period) shift(n int) period {
+	switch p.kind {
+	case "monthly":
+		return periodOf(p.kind, p.start.AddDate(0, n, 0))
+	case "quarterly":
+		return periodOf(p.kind, p.start.AddDate(0, 3*n, 0))
+	default:
+		return periodOf(p.kind, p.start.AddDate(0, 0, 7*n))
+	}
+}
+
+// isFuture reports whether the period hasn't started yet.
+func (p period) isFuture(now time.Time) bool {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return p.start.After(today)
+}
+
+// periodInput is the period field widget: a picker of recent periods,
+// newest first, ending with the current one unless future periods are allowed.
+type periodInput struct {
+	sel *widget.Select
+}
+
+func newPeriodInput(f Field, locale Locale, onChanged func()) *periodInput {
+	kind := f.PeriodType
+	back := map[string]int{"monthly": 24, "quarterly": 8}[kind]
+	if back == 0 {
+		back = 52
+	}
+	// future periods are offered when allowed, or when choosing one only warns
+	ahead := 0
+	if v := f.Validation; v.AllowFuture || containsString(v.Warnings, "allowFuture") {
+		ahead = 4
+	}
+
+	current := periodOf(kind, time.Now())
+	var opts []string
+	for n := ahead; n > -back; n-- {
+		opts = append(opts, current.shift(n).Label())
+	}
+	in := &periodInput{sel: widget.NewSelect(opts, func(string) { onChanged() })}
+	in.sel.PlaceHolder = "Select period..."
+	if placeholder := f.Placeholder.In(locale); placeholder != "" {
+		in.sel.PlaceHolder = placeholder
+	}
+	return in
+}
+
+func (in *periodInput) content() fyne.CanvasObject {
+	return in.sel
+}
+
+// Value returns the ID of the picked period.
+func (in *periodInput) Value() string {
+	id, _, _ := strings.Cut(in.sel.Selected, " · ")
+	return id
+}
+
+// SetValue picks a period by ID, adding it to the list if it is older than
+// the periods offered.
+func (in *periodInput) SetValue(id string) {
+	p, err := parsePeriod(id)
+	if id == "" || err != nil {
+		in.sel.ClearSelected()
+		return
+	}
+	label := p.Label()
+	if !containsString(in.sel.Options, label) {
+		in.sel.Options = append(in.sel.Options, label)
+	}
+	in.sel.SetSelected(label)
+}
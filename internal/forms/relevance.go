@@ -0,0 +1,45 @@
+package forms
+
+import "strings"
+
+// isRelevant evaluates a relevance expression against the current values.
+// An empty expression is always relevant; a broken one fails open so that a
+// bad form definition never hides a question from the user. formlint reports
+// broken expressions, so they aren't reported here on every change.
+func isRelevant(expr string, values map[string]string) bool {
+	if strings.TrimSpace(expr) == "" {
+		return true
+	}
+	ok, err := evalFormula(expr, values)
+	return ok || err != nil
+}
+
+// relevantFields returns the top-level fields that are currently shown, i.e.
+// whose own relevance and whose section's relevance both hold. Repeat sections
+// are skipped; see shownInstanceFields.
+func relevantFields(sections []Section, values map[string]string) []Field {
+	var fields []Field
+	for _, sec := range sections {
+		if sec.Repeat || !isRelevant(sec.Relevant, values) {
+			continue
+		}
+		for _, f := range sec.Fields {
+			if isRelevant(f.Relevant, values) {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// shownInstanceFields returns the fields shown in one instance of a repeat
+// section, given the top-level values merged with the instance's own.
+func shownInstanceFields(sec Section, values map[string]string) []Field {
+	var fields []Field
+	for _, f := range sec.Fields {
+		if isRelevant(f.Relevant, values) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,81 @@
+package forms
+
+import (
+	"regexp"
+	"strings"
+)
+
+var nonKeyChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Key returns the payload key of a repeat section: its ID, or a snake_case
+// version of its title when no ID is set. Sections with a translated title
+// need an ID for a key that translations don't change; without one, the
+// first translation by locale is used.
+func (s Section) Key() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return strings.Trim(nonKeyChars.ReplaceAllString(strings.ToLower(s.Title.In(Locale{})), "_"), "_")
+}
+
+// splitPayload separates a nested form payload into the top-level values and
+// the instances of each repeat section, as widget text. It accepts freshly
+// built payloads, typed JSON drafts and older string-only drafts alike.
+func splitPayload(data map[string]any) (map[string]string, map[string][]map[string]string) {
+	values := map[string]string{}
+	repeats := map[string][]map[string]string{}
+	for k, v := range data {
+		if rows, ok := repeatRows(v); ok {
+			repeats[k] = rows
+			continue
+		}
+		values[k] = rawValue(v)
+	}
+	return values, repeats
+}
+
+// repeatRows recognises the instances of a repeat section in a payload.
+func repeatRows(v any) ([]map[string]string, bool) {
+	var out []map[string]string
+	switch rows := v.(type) {
+	case []map[string]string:
+		return rows, true
+	case []map[string]any:
+		for _, row := range rows {
+			out = append(out, stringValues(row))
+		}
+		return out, true
+	case []any:
+		// a list of objects; anything else is the choices of a select_multiple
+		for _, row := range rows {
+			m, ok := row.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, stringValues(m))
+		}
+		return out, len(out) > 0
+	}
+	return nil, false
+}
+
+// stringValues flattens one decoded repeat instance into string values.
+func stringValues(row map[string]any) map[string]string {
+	out := make(map[string]string, len(row))
+	for k, v := range row {
+		out[k] = rawValue(v)
+	}
+	return out
+}
+
+// mergeValues overlays the values of a repeat instance on the top-level ones.
+func mergeValues(base, scoped map[string]string) map[string]string {
+	out := make(map[string]string, len(base)+len(scoped))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range scoped {
+		out[k] = v
+	}
+	return out
+}
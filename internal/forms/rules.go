@@ -0,0 +1,317 @@
+package forms
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// rules returns every check of a field in the order they are applied: those
+// set directly on Validation, one rule each, then Rules. Checks listed in
+// Warnings become warning rules, and ErrorMessage is the formula's message.
+func (v Validation) rules() []Rule {
+	var rules []Rule
+	add := func(name string, r Rule) {
+		r.Warning = containsString(v.Warnings, name)
+		rules = append(rules, r)
+	}
+	if v.Required {
+		add("required", Rule{Required: true})
+	}
+	if v.MinLength > 0 {
+		add("minLength", Rule{MinLength: v.MinLength})
+	}
+	if v.MaxLength > 0 {
+		add("maxLength", Rule{MaxLength: v.MaxLength})
+	}
+	if v.MinSelected > 0 {
+		add("minSelected", Rule{MinSelected: v.MinSelected})
+	}
+	if v.MaxSelected > 0 {
+		add("maxSelected", Rule{MaxSelected: v.MaxSelected})
+	}
+	if v.MaxSize > 0 {
+		add("maxSize", Rule{MaxSize: v.MaxSize})
+	}
+	if len(v.AllowedTypes) > 0 {
+		add("allowedTypes", Rule{AllowedTypes: v.AllowedTypes})
+	}
+	if v.Min != nil {
+		add("min", Rule{Min: v.Min})
+	}
+	if v.Max != nil {
+		add("max", Rule{Max: v.Max})
+	}
+	if v.Pattern != "" {
+		add("pattern", Rule{Pattern: v.Pattern})
+	}
+	if v.MinDate != "" {
+		add("minDate", Rule{MinDate: v.MinDate})
+	}
+	if v.MaxDate != "" {
+		add("maxDate", Rule{MaxDate: v.MaxDate})
+	}
+	for _, ref := range []struct {
+		name string
+		rule Rule
+	}{
+		{"greaterThanField", Rule{GreaterThanField: v.GreaterThanField}},
+		{"lessThanField", Rule{LessThanField: v.LessThanField}},
+		{"beforeField", Rule{BeforeField: v.BeforeField}},
+		{"afterField", Rule{AfterField: v.AfterField}},
+		{"equalToField", Rule{EqualToField: v.EqualToField}},
+		{"notEqualToField", Rule{NotEqualToField: v.NotEqualToField}},
+	} {
+		if len(ref.rule.checks()) > 0 {
+			add(ref.name, ref.rule)
+		}
+	}
+	if v.Formula != "" {
+		add("formula", Rule{Formula: v.Formula, Message: v.ErrorMessage})
+	}
+	return append(rules, v.Rules...)
+}
+
+// limits returns the hard limits inputs enforce while the user types or
+// picks: maxLength, minDate and maxDate, maxSize and allowedTypes. Rules that
+// only warn don't limit input.
+func (v Validation) limits() Validation {
+	var l Validation
+	for _, r := range v.rules() {
+		if r.Warning {
+			continue
+		}
+		if r.MaxLength > 0 {
+			l.MaxLength = r.MaxLength
+		}
+		if r.MinDate != "" {
+			l.MinDate = r.MinDate
+		}
+		if r.MaxDate != "" {
+			l.MaxDate = r.MaxDate
+		}
+		if r.MaxSize > 0 {
+			l.MaxSize = r.MaxSize
+		}
+		if len(r.AllowedTypes) > 0 {
+			l.AllowedTypes = r.AllowedTypes
+		}
+	}
+	return l
+}
+
+// checks names the checks a rule sets, by JSON name.
+func (r Rule) checks() []string {
+	var names []string
+	for _, c := range []struct {
+		name string
+		set  bool
+	}{
+		{"required", r.Required},
+		{"minLength", r.MinLength > 0},
+		{"maxLength", r.MaxLength > 0},
+		{"min", r.Min != nil},
+		{"max", r.Max != nil},
+		{"pattern", r.Pattern != ""},
+		{"minDate", r.MinDate != ""},
+		{"maxDate", r.MaxDate != ""},
+		{"greaterThanField", r.GreaterThanField != ""},
+		{"lessThanField", r.LessThanField != ""},
+		{"beforeField", r.BeforeField != ""},
+		{"afterField", r.AfterField != ""},
+		{"equalToField", r.EqualToField != ""},
+		{"notEqualToField", r.NotEqualToField != ""},
+		{"minSelected", r.MinSelected > 0},
+		{"maxSelected", r.MaxSelected > 0},
+		{"formula", r.Formula != ""},
+		{"maxSize", r.MaxSize > 0},
+		{"allowedTypes", len(r.AllowedTypes) > 0},
+	} {
+		if c.set {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// check applies a rule to a field's value, which has already been read as
+// the field's type, and returns the messages of the checks that fail, or
+// the rule's own message if it has one. Empty values are only checked by
+// "required" and formulas. values holds the scope's values, for cross-field
+// checks and formulas; files finds attachments, which are not checked when nil.
+func (r Rule) check(f Field, val string, values map[string]string, files AttachmentFiles, locale Locale) []string {
+	label := f.Label.In(locale)
+	var failed []string
+	fail := func(format string, args ...any) {
+		failed = append(failed, fmt.Sprintf(format, args...))
+	}
+
+	if r.Required {
+		switch f.Type {
+		case "boolean":
+			if val == "" || val == "false" {
+				fail("'%s' must be checked to proceed", label)
+			}
+		case "signature":
+			if val == "" {
+				fail("'%s' must be signed", label)
+			}
+		default:
+			if val == "" {
+				fail("'%s' is required", label)
+			}
+		}
+	}
+	if val != "" {
+		r.checkValue(f, val, label, values, files, fail)
+	}
+
+	// ---------- Formula-based validation ----------
+	if r.Formula != "" {
+		ok, err := evalFormula(r.Formula, values)
+		if err != nil {
+			fail("Invalid formula for '%s': %v", label, err)
+		} else if !ok {
+			fail("Formula validation failed for '%s'", label)
+		}
+	}
+
+	// the rule's own message replaces the default ones
+	if msg := r.Message.In(locale); msg != "" && len(failed) > 0 {
+		return []string{msg}
+	}
+	return failed
+}
+
+// checkValue applies the checks of a rule that only concern values that
+// were filled in.
+func (r Rule) checkValue(f Field, val, label string, values map[string]string, files AttachmentFiles, fail func(format string, args ...any)) {
+	// ---------- Length and selection count ----------
+	if r.MinLength > 0 && len(val) < r.MinLength {
+		fail("'%s' must be at least %d characters", label, r.MinLength)
+	}
+	if r.MaxLength > 0 && len(val) > r.MaxLength {
+		fail("'%s' must be no more than %d characters", label, r.MaxLength)
+	}
+	if f.Type == "select_multiple" {
+		count := len(splitList(val))
+		if r.MinSelected > 0 && count < r.MinSelected {
+			fail("'%s' needs at least %d selections", label, r.MinSelected)
+		}
+		if r.MaxSelected > 0 && count > r.MaxSelected {
+			fail("'%s' allows at most %d selections", label, r.MaxSelected)
+		}
+	}
+
+	// ---------- Numeric range ----------
+	if isNumeric(f.Type) {
+		num, _ := strconv.ParseFloat(val, 64)
+		if r.Min != nil && num < *r.Min {
+			fail("'%s' must be at least %s", label, formatNumber(*r.Min))
+		}
+		if r.Max != nil && num > *r.Max {
+			fail("'%s' must be at most %s", label, formatNumber(*r.Max))
+		}
+	}
+
+	// ---------- Regex ----------
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err == nil && !re.MatchString(val) {
+			fail("'%s' does not match expected format", label)
+		}
+	}
+
+	// ---------- Date/time and period range ----------
+	// Relative bounds ("today", "-7d", "start_of_week") resolve against now
+	now := time.Now()
+	if isTemporal(f.Type) {
+		dateVal, _ := parseTemporal(f.Type, val)
+		if minDate, ok := resolveBound(f.Type, r.MinDate, now, false); r.MinDate != "" && ok && dateVal.Before(minDate) {
+			fail("'%s' must be after %s", label, formatTemporal(f.Type, minDate))
+		}
+		if maxDate, ok := resolveBound(f.Type, r.MaxDate, now, true); r.MaxDate != "" && ok && dateVal.After(maxDate) {
+			fail("'%s' must be before %s", label, formatTemporal(f.Type, maxDate))
+		}
+	}
+	if f.Type == "period" {
+		p, _ := parsePeriod(val)
+		if minDate, ok := resolveBound("date", r.MinDate, now, false); r.MinDate != "" && ok && p.end.Before(minDate) {
+			fail("'%s' must be after %s", label, formatTemporal("date", minDate))
+		}
+		if maxDate, ok := resolveBound("date", r.MaxDate, now, true); r.MaxDate != "" && ok && p.start.After(maxDate) {
+			fail("'%s' must be before %s", label, formatTemporal("date", maxDate))
+		}
+	}
+
+	// ---------- Cross-field checks ----------
+	if other := values[r.GreaterThanField]; r.GreaterThanField != "" && other != "" {
+		if isNumeric(f.Type) {
+			numA, _ := strconv.ParseFloat(val, 64)
+			numB, _ := strconv.ParseFloat(other, 64)
+			if numA <= numB {
+				fail("'%s' must be greater than '%s'", label, r.GreaterThanField)
+			}
+		} else if isTemporal(f.Type) || f.Type == "period" {
+			dateA, _ := parseAnyTemporal(val)
+			dateB, _ := parseAnyTemporal(other)
+			if !dateA.After(dateB) {
+				fail("'%s' must be after '%s'", label, r.GreaterThanField)
+			}
+		}
+	}
+	if other := values[r.LessThanField]; r.LessThanField != "" && other != "" {
+		if isNumeric(f.Type) {
+			numA, _ := strconv.ParseFloat(val, 64)
+			numB, _ := strconv.ParseFloat(other, 64)
+			if numA >= numB {
+				fail("'%s' must be less than '%s'", label, r.LessThanField)
+			}
+		} else if isTemporal(f.Type) || f.Type == "period" {
+			dateA, _ := parseAnyTemporal(val)
+			dateB, _ := parseAnyTemporal(other)
+			if !dateA.Before(dateB) {
+				fail("'%s' must be before '%s'", label, r.LessThanField)
+			}
+		}
+	}
+	if other := values[r.BeforeField]; r.BeforeField != "" && other != "" {
+		dateA, ok1 := parseAnyTemporal(val)
+		dateB, ok2 := parseAnyTemporal(other)
+		if ok1 && ok2 && !dateA.Before(dateB) {
+			fail("'%s' must be before '%s'", label, r.BeforeField)
+		}
+	}
+	if other := values[r.AfterField]; r.AfterField != "" && other != "" {
+		dateA, ok1 := parseAnyTemporal(val)
+		dateB, ok2 := parseAnyTemporal(other)
+		if ok1 && ok2 && !dateA.After(dateB) {
+			fail("'%s' must be after '%s'", label, r.AfterField)
+		}
+	}
+	if other := values[r.EqualToField]; r.EqualToField != "" && other != "" && val != other {
+		fail("'%s' must equal '%s'", label, r.EqualToField)
+	}
+	if other := values[r.NotEqualToField]; r.NotEqualToField != "" && other != "" && val == other {
+		fail("'%s' must not equal '%s'", label, r.NotEqualToField)
+	}
+
+	// ---------- Attachment size and type ----------
+	if f.Type == "attachment" && files != nil && (r.MaxSize > 0 || len(r.AllowedTypes) > 0) {
+		file, err := files.Lookup(val)
+		if err != nil {
+			return
+		}
+		if r.MaxSize > 0 && file.Size > r.MaxSize {
+			fail("'%s': file is larger than %s", label, formatSize(r.MaxSize))
+		}
+		if t := detectMimeType(file.Name, file.Head); !mimeAllowed(t, r.AllowedTypes) {
+			fail("'%s': file type %s is not allowed", label, t)
+		}
+	}
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
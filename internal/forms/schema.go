@@ -0,0 +1,294 @@
+package forms
+
+//go:generate go run ../../cmd/formschema -o assets/forms.schema.json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// schemaID identifies the generated schema; forms.json refers to it through
+// "$schema" so editors can offer completion and validation.
+const schemaID = "https://forms-app/forms.schema.json"
+
+// schemaRequired lists the properties a bundle can't do without, by type.
+var schemaRequired = map[string][]string{
+	"FormBundle":     {"forms"},
+	"FormDefinition": {"sections"},
+	"Section":        {"fields"},
+	"Field":          {"id"},
+	"Choice":         {"value"},
+	"Option":         {"value"},
+}
+
+// schemaEnums restricts string properties to known values, by type and
+// property. An empty layout is the default, as when the key is left out, and
+// calculate-only fields have an empty type.
+var schemaEnums = map[string]map[string][]string{
+	"FormDefinition": {"layout": {"", "tabs", "wizard"}},
+	"Field":          {"type": append([]string{""}, sortedKeys(fieldTypes)...), "periodType": sortedKeys(periodTypes)},
+	"Section":        {"layout": {"", "grid", "stack"}},
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// BundleSchema returns a JSON Schema describing forms.json, generated from
+// FormBundle and the types it contains. Run "go generate ./internal/forms"
+// after changing them to refresh assets/forms.schema.json.
+func BundleSchema() map[string]any {
+	defs := make(map[string]any)
+	root := schemaFor(reflect.TypeOf(FormBundle{}), defs)
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     schemaID,
+		"title":   "Form bundle",
+		"$ref":    root["$ref"],
+		"$defs":   defs,
+	}
+}
+
+// textSchema describes Text: a plain string or translations by locale.
+var textSchema = map[string]any{
+	"anyOf": []any{
+		map[string]any{"type": "string"},
+		map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+	},
+}
+
+// schemaFor describes a Go type, adding struct types to defs as it goes.
+func schemaFor(t reflect.Type, defs map[string]any) map[string]any {
+	if t == reflect.TypeOf(Text(nil)) {
+		defs["Text"] = textSchema
+		return map[string]any{"$ref": "#/$defs/Text"}
+	}
+	// Option: a plain string, or a value with a label
+	if t == reflect.TypeOf(Option{}) {
+		defs["Option"] = map[string]any{
+			"anyOf": []any{map[string]any{"type": "string"}, structSchema(t, defs)},
+		}
+		return map[string]any{"$ref": "#/$defs/Option"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Pointer:
+		// optional values, omitted when unset
+		return schemaFor(t.Elem(), defs)
+	case reflect.Slice:
+		// Go writes nil slices and maps as null
+		return map[string]any{"type": []any{"array", "null"}, "items": schemaFor(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": []any{"object", "null"}, "additionalProperties": schemaFor(t.Elem(), defs)}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := defs[name]; !ok {
+			defs[name] = nil // reserve the name in case the type refers to itself
+			defs[name] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+	panic(fmt.Sprintf("schemaFor: unsupported type %s", t))
+}
+
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	props := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		prop := schemaFor(sf.Type, defs)
+		if values, ok := schemaEnums[t.Name()][name]; ok {
+			prop["enum"] = values
+		}
+		props[name] = prop
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if required, ok := schemaRequired[t.Name()]; ok {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// BundleSchemaJSON returns BundleSchema as indented JSON.
+func BundleSchemaJSON() ([]byte, error) {
+	b, err := json.MarshalIndent(BundleSchema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// SchemaError lists where a bundle departs from the schema.
+type SchemaError struct {
+	Issues []LintIssue
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		msgs[i] = issue.Path + ": " + issue.Message
+	}
+	return "form bundle doesn't match the schema: " + strings.Join(msgs, "; ")
+}
+
+// ValidateBundleJSON checks raw forms.json bytes against BundleSchema. It
+// returns a *SchemaError listing every mismatch, or the JSON syntax error.
+func ValidateBundleJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	schema := BundleSchema()
+	var issues []LintIssue
+	checkSchema(schema, schema["$defs"].(map[string]any), doc, "$", &issues)
+	if len(issues) > 0 {
+		return &SchemaError{Issues: issues}
+	}
+	return nil
+}
+
+// checkSchema validates v against the subset of JSON Schema that
+// BundleSchema generates: $ref, anyOf, type, enum, properties, required,
+// additionalProperties and items.
+func checkSchema(schema, defs map[string]any, v any, path string, issues *[]LintIssue) {
+	if ref, ok := schema["$ref"].(string); ok {
+		schema = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+	}
+	if options, ok := schema["anyOf"].([]any); ok {
+		// report the issues of the closest match
+		var closest []LintIssue
+		for i, option := range options {
+			var got []LintIssue
+			checkSchema(option.(map[string]any), defs, v, path, &got)
+			if len(got) == 0 {
+				return
+			}
+			if i == 0 || len(got) < len(closest) {
+				closest = got
+			}
+		}
+		*issues = append(*issues, closest...)
+		return
+	}
+	report := func(format string, args ...any) {
+		*issues = append(*issues, LintIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if want, ok := schema["type"]; ok {
+		var types []string
+		switch want := want.(type) {
+		case string:
+			types = []string{want}
+		case []any:
+			for _, t := range want {
+				types = append(types, t.(string))
+			}
+		}
+		if !matchesAnyType(v, types) {
+			report("expected %s, got %s", strings.Join(types, " or "), jsonTypeOf(v))
+			return
+		}
+	}
+	if values, ok := schema["enum"].([]string); ok {
+		if s, _ := v.(string); !containsString(values, s) {
+			report("%q is not one of %s", s, strings.Join(values, ", "))
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]string)
+		for _, key := range required {
+			if _, ok := v[key]; !ok {
+				report("missing required property %q", key)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := path + "." + key
+			if prop, ok := props[key].(map[string]any); ok {
+				checkSchema(prop, defs, v[key], child, issues)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					*issues = append(*issues, LintIssue{Path: child, Message: "unknown property"})
+				}
+			case map[string]any:
+				checkSchema(extra, defs, v[key], child, issues)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				checkSchema(items, defs, item, fmt.Sprintf("%s[%d]", path, i), issues)
+			}
+		}
+	}
+}
+
+func matchesAnyType(v any, types []string) bool {
+	got := jsonTypeOf(v)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf names the JSON type of a value decoded with UseNumber.
+func jsonTypeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,106 @@
+package forms
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"forms-app/internal/ui/signature"
+)
+
+// signatureInput is the signature field widget. Every change re-exports the
+// drawing as a PNG in attachment storage, so it is saved with drafts and
+// uploaded like any other attachment.
+type signatureInput struct {
+	pad     *signature.Pad
+	preview *canvas.Image // a signature loaded from a draft; strokes can't be restored
+	path    string
+	owned   bool // path was written by this widget and may be overwritten
+	content fyne.CanvasObject
+}
+
+func newSignatureInput(a fyne.App, onChanged func()) *signatureInput {
+	in := &signatureInput{}
+	in.preview = canvas.NewImageFromFile("")
+	in.preview.FillMode = canvas.ImageFillContain
+	in.preview.SetMinSize(fyne.NewSize(300, 150))
+	in.preview.Hide()
+
+	in.pad = signature.NewPad(func() {
+		if err := in.export(a); err != nil {
+			fmt.Println("⚠️ Failed to save signature:", err)
+		}
+		onChanged()
+	})
+
+	undo := widget.NewButtonWithIcon("", theme.ContentUndoIcon(), in.pad.Undo)
+	redo := widget.NewButtonWithIcon("", theme.ContentRedoIcon(), in.pad.Redo)
+	clear := widget.NewButtonWithIcon("Clear", theme.ContentClearIcon(), func() {
+		in.preview.Hide()
+		in.pad.Show()
+		in.pad.Clear()
+	})
+
+	in.content = container.NewVBox(
+		container.NewStack(in.pad, in.preview),
+		container.NewHBox(undo, redo, clear),
+	)
+	return in
+}
+
+// export writes the current drawing to disk, or forgets it when the pad is empty.
+func (in *signatureInput) export(a fyne.App) error {
+	if in.pad.IsEmpty() {
+		in.discard()
+		return nil
+	}
+	data, err := in.pad.PNG()
+	if err != nil {
+		return err
+	}
+	if !in.owned {
+		dir := attachmentDir(a)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		in.path = filepath.Join(dir, fmt.Sprintf("%d-signature.png", time.Now().UnixNano()))
+		in.owned = true
+	}
+	return os.WriteFile(in.path, data, 0644)
+}
+
+// discard drops the current signature, deleting the PNG if this widget wrote it.
+func (in *signatureInput) discard() {
+	if in.owned {
+		_ = os.Remove(in.path)
+	}
+	in.path = ""
+	in.owned = false
+}
+
+// Value returns the path of the exported PNG, or "" when unsigned.
+func (in *signatureInput) Value() string {
+	return in.path
+}
+
+// SetValue shows a previously saved signature until the user clears it.
+func (in *signatureInput) SetValue(path string) {
+	in.discard()
+	in.path = path
+	if path == "" {
+		in.preview.Hide()
+		in.pad.Show()
+		return
+	}
+	in.preview.File = path
+	in.preview.Refresh()
+	in.preview.Show()
+	in.pad.Hide()
+}
@@ -0,0 +1,84 @@
+package forms
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+)
+
+// currentUserKey is the preference holding the logged-in user, so that sticky
+// values aren't shared between people using the same device.
+const currentUserKey = "currentUser"
+
+// SetCurrentUser records who is logged in; sticky values are kept per user.
+func SetCurrentUser(a fyne.App, user string) {
+	a.Preferences().SetString(currentUserKey, user)
+}
+
+// stickyKey is the preference holding the current user's sticky values as a
+// JSON object keyed by field ID.
+func stickyKey(a fyne.App) string {
+	return "sticky." + a.Preferences().String(currentUserKey)
+}
+
+func loadStickyValues(a fyne.App) map[string]string {
+	remembered := make(map[string]string)
+	raw := a.Preferences().String(stickyKey(a))
+	if raw == "" {
+		return remembered
+	}
+	if err := json.Unmarshal([]byte(raw), &remembered); err != nil {
+		fmt.Printf("⚠️ ignoring sticky values: %v\n", err)
+	}
+	return remembered
+}
+
+// withStickyValues returns values plus the remembered value of every sticky
+// field that has no value yet. The value may have been submitted from any
+// form with a field of the same ID.
+func withStickyValues(a fyne.App, fields []Field, values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for id, v := range values {
+		out[id] = v
+	}
+	remembered := loadStickyValues(a)
+	for _, f := range fields {
+		if !f.Sticky {
+			continue
+		}
+		if _, ok := out[f.ID]; ok {
+			continue
+		}
+		if v, ok := remembered[f.ID]; ok {
+			out[f.ID] = v
+		}
+	}
+	return out
+}
+
+// rememberStickyValues stores the submitted values of sticky fields.
+func rememberStickyValues(a fyne.App, fields []Field, values map[string]string) {
+	remembered := loadStickyValues(a)
+	changed := false
+	for _, f := range fields {
+		if v := values[f.ID]; f.Sticky && v != "" {
+			remembered[f.ID] = v
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	raw, err := json.Marshal(remembered)
+	if err != nil {
+		fmt.Printf("⚠️ failed to save sticky values: %v\n", err)
+		return
+	}
+	a.Preferences().SetString(stickyKey(a), string(raw))
+}
+
+// ClearStickyValues forgets the current user's remembered field values.
+func ClearStickyValues(a fyne.App) {
+	a.Preferences().RemoveValue(stickyKey(a))
+}
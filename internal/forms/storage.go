@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -20,6 +21,7 @@ func LoadForms(a fyne.App, apiURL, appName string) (map[string]FormDefinition, [
 	if err != nil {
 		if cache.Forms != nil {
 			fmt.Println("⚠️ Using cached forms:", err)
+			cache.prepare()
 			return cache.Forms, cache.FormOrder, "cache", nil
 		}
 		return nil, nil, "error", fmt.Errorf("no network and no cached forms available")
@@ -28,6 +30,7 @@ func LoadForms(a fyne.App, apiURL, appName string) (map[string]FormDefinition, [
 	// Compare versions
 	if cache.Version != "" && cache.Version == serverBundle.Version {
 		fmt.Println("✅ Forms up to date (version", cache.Version, ")")
+		cache.prepare()
 		return cache.Forms, cache.FormOrder, "cache", nil
 	}
 
@@ -43,6 +46,7 @@ func LoadForms(a fyne.App, apiURL, appName string) (map[string]FormDefinition, [
 		fmt.Println("⚠️ Failed to update cache:", err)
 	}
 	fmt.Println("⬇️  Updated forms cache to version", serverBundle.Version)
+	serverBundle.prepare()
 
 	return serverBundle.Forms, serverBundle.FormOrder, "api", nil
 }
@@ -68,7 +72,15 @@ func fetchBundleFromAPI(url string) (FormBundle, error) {
 		return bundle, fmt.Errorf("server returned %s", resp.Status)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return bundle, err
+	}
+	// a malformed bundle must not replace a working cached one
+	if err := ValidateBundleJSON(body); err != nil {
+		return bundle, err
+	}
+	if err := json.Unmarshal(body, &bundle); err != nil {
 		return bundle, err
 	}
 	return bundle, nil
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -17,22 +18,27 @@ import (
 // SubmitForm sends a filled form to the backend API.
 // If the network is unreachable or the server returns an error,
 // it saves the payload locally as a standardized draft.
-func SubmitForm(a fyne.App, apiURL, formName string, payload map[string]string) error {
-	// Prepare JSON body for submission
-	body, err := json.Marshal(map[string]any{
-		"form": formName,
-		"data": payload,
-	})
+// Values are typed (numbers, booleans, ISO dates); repeat sections appear
+// in the payload as a list of per-instance objects. Payloads with attachments
+// are sent as multipart, and the stored files no draft refers to are removed
+// once the upload succeeds.
+// Warnings the user confirmed are sent as "warnings", next to "data".
+func SubmitForm(a fyne.App, apiURL, formName string, payload map[string]any) error {
+	// Prepare body for submission
+	body, contentType, parts, err := encodeSubmission(formName, payload)
 	if err != nil {
 		return fmt.Errorf("marshal error: %v", err)
 	}
 
 	client := &http.Client{Timeout: 15 * time.Second}
+	if len(parts) > 0 {
+		client.Timeout = 2 * time.Minute
+	}
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("request creation error: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 
 	// Perform the network request
 	resp, err := client.Do(req)
@@ -61,6 +67,7 @@ func SubmitForm(a fyne.App, apiURL, formName string, payload map[string]string)
 
 	// ✅ Success (200–299)
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		removeAttachments(a, parts)
 		return nil
 	}
 
@@ -82,6 +89,54 @@ func SubmitForm(a fyne.App, apiURL, formName string, payload map[string]string)
 	return errors.New(fmt.Sprintf("server error (%d): %s", resp.StatusCode, string(respBody)))
 }
 
+// encodeSubmission builds the request body: plain JSON, or multipart with the
+// JSON in a "payload" field and one file part per attachment.
+func encodeSubmission(formName string, payload map[string]any) ([]byte, string, []attachmentPart, error) {
+	var parts []attachmentPart
+	data := stripAttachments(payload, "", &parts)
+
+	envelope := map[string]any{
+		"form": formName,
+		"data": data,
+	}
+	if warnings, ok := data[warningsKey]; ok {
+		delete(data, warningsKey)
+		envelope["warnings"] = warnings
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(parts) == 0 {
+		return body, "application/json", nil, nil
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("payload", string(body)); err != nil {
+		return nil, "", nil, err
+	}
+	for _, p := range parts {
+		fw, err := mw.CreateFormFile(p.part, p.name)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		f, err := os.Open(p.path)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("attachment %s: %v", p.name, err)
+		}
+		_, err = io.Copy(fw, f)
+		f.Close()
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("attachment %s: %v", p.name, err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", nil, err
+	}
+	return buf.Bytes(), mw.FormDataContentType(), parts, nil
+}
+
 func saveDraft(a fyne.App, formName string, payload map[string]string) error {
 	root := a.Storage().RootURI().Path()
 	if root == "" {
@@ -137,14 +192,16 @@ func LoadDrafts(a fyne.App) ([]string, error) {
 }
 
 type Draft struct {
-	Form  string            `json:"form"`
-	Data  map[string]string `json:"data"`
-	Meta  map[string]any    `json:"meta"`
-	Error map[string]any    `json:"error"`
+	Form  string         `json:"form"`
+	Data  map[string]any `json:"data"` // typed values; repeat sections hold a list of instances
+	Meta  map[string]any `json:"meta"`
+	Error map[string]any `json:"error"`
 }
 
 // RetryDraft tries to re-upload a saved draft file and deletes it on success.
-func RetryDraft(a fyne.App, apiURL, draftPath string) error {
+// defs are the loaded form definitions, by name; the draft's one retypes
+// values of drafts saved before payloads were typed.
+func RetryDraft(a fyne.App, apiURL, draftPath string, defs map[string]FormDefinition) error {
 	data, err := os.ReadFile(draftPath)
 	if err != nil {
 		return fmt.Errorf("failed to read draft: %v", err)
@@ -159,16 +216,24 @@ func RetryDraft(a fyne.App, apiURL, draftPath string) error {
 		return fmt.Errorf("draft missing form or data fields")
 	}
 
+	// Drafts saved before payloads were typed hold strings only
+	if def, ok := defs[d.Form]; ok {
+		retypeDraft(def, d.Data)
+	}
+
 	// Try submission
 	err = SubmitForm(a, apiURL, d.Form, d.Data)
 	if err != nil {
 		return fmt.Errorf("retry failed: %v", err)
 	}
 
-	// On success → delete file
+	// On success → delete file, then the copies no other draft refers to
 	if rmErr := os.Remove(draftPath); rmErr != nil {
 		return fmt.Errorf("submitted but failed to delete draft: %v", rmErr)
 	}
+	var parts []attachmentPart
+	stripAttachments(d.Data, "", &parts)
+	removeAttachments(a, parts)
 
 	fyne.CurrentApp().SendNotification(&fyne.Notification{
 		Title:   "✅ Draft Uploaded",
@@ -206,7 +271,19 @@ func SaveTaggedDraft(a fyne.App, formName string, payload map[string]any) error
 	return nil
 }
 
-// DeleteDraft removes a saved draft file.
+// DeleteDraft removes a saved draft file along with the attachments no other
+// draft refers to.
 func DeleteDraft(a fyne.App, path string) error {
-	return os.Remove(path)
+	var parts []attachmentPart
+	if data, err := os.ReadFile(path); err == nil {
+		var d Draft
+		if json.Unmarshal(data, &d) == nil {
+			stripAttachments(d.Data, "", &parts)
+		}
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	removeAttachments(a, parts)
+	return nil
 }
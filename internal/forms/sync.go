@@ -18,7 +18,7 @@ var (
 
 // StartAutoSync runs a background goroutine that periodically syncs drafts.
 // It checks the preference "autoSyncEnabled" and only runs if true.
-func StartAutoSync(a fyne.App, apiURL string) {
+func StartAutoSync(a fyne.App, apiURL string, defs map[string]FormDefinition) {
 	autoSyncMutex.Lock()
 	if autoSyncRunning {
 		autoSyncMutex.Unlock()
@@ -59,7 +59,7 @@ func StartAutoSync(a fyne.App, apiURL string) {
 					continue
 				}
 				path := filepath.Join(draftDir, e.Name())
-				if err := RetryDraft(a, apiURL, path); err != nil {
+				if err := RetryDraft(a, apiURL, path, defs); err != nil {
 					failed++
 				} else {
 					success++
@@ -80,7 +80,7 @@ func StartAutoSync(a fyne.App, apiURL string) {
 
 // ManualSync tries to upload all drafts immediately.
 // Returns (successCount, failedCount)
-func ManualSync(a fyne.App, apiURL string) (int, int) {
+func ManualSync(a fyne.App, apiURL string, defs map[string]FormDefinition) (int, int) {
 	root := a.Storage().RootURI().Path()
 	if root == "" {
 		home, _ := os.UserHomeDir()
@@ -98,7 +98,7 @@ func ManualSync(a fyne.App, apiURL string) (int, int) {
 			continue
 		}
 		path := filepath.Join(draftDir, e.Name())
-		if err := RetryDraft(a, apiURL, path); err != nil {
+		if err := RetryDraft(a, apiURL, path, defs); err != nil {
 			failed++
 		} else {
 			success++
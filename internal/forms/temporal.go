@@ -0,0 +1,159 @@
+package forms
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISO 8601 layouts used for the text of temporal fields, in drafts and payloads.
+const (
+	dateLayout     = "2006-01-02"
+	timeLayout     = "15:04"
+	dateTimeLayout = "2006-01-02T15:04"
+)
+
+// isTemporal reports whether a field type holds a date, a time or both.
+func isTemporal(fieldType string) bool {
+	switch fieldType {
+	case "date", "time", "datetime":
+		return true
+	}
+	return false
+}
+
+// temporalLayouts lists the accepted input layouts per type, canonical first.
+func temporalLayouts(fieldType string) []string {
+	switch fieldType {
+	case "time":
+		return []string{timeLayout, "15:04:05"}
+	case "datetime":
+		return []string{dateTimeLayout, "2006-01-02T15:04:05", time.RFC3339, "2006-01-02 15:04"}
+	default:
+		return []string{dateLayout}
+	}
+}
+
+// parseTemporal parses the text of a date, time or datetime field.
+func parseTemporal(fieldType, raw string) (time.Time, error) {
+	for _, layout := range temporalLayouts(fieldType) {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a valid %s", raw, temporalNoun(fieldType))
+}
+
+// formatTemporal renders a value in the canonical layout of its type.
+func formatTemporal(fieldType string, t time.Time) string {
+	return t.Format(temporalLayouts(fieldType)[0])
+}
+
+// parseAnyTemporal parses text whose temporal type isn't known, such as an
+// expression parameter or the value of another field in a cross-field rule.
+// Period IDs stand for the first day of the period.
+func parseAnyTemporal(raw string) (time.Time, bool) {
+	for _, fieldType := range []string{"date", "datetime", "time"} {
+		if t, err := parseTemporal(fieldType, raw); err == nil {
+			return t, true
+		}
+	}
+	if p, err := parsePeriod(raw); err == nil {
+		return p.start, true
+	}
+	return time.Time{}, false
+}
+
+// relativeBound matches bounds such as "today", "-7d", "start_of_week-4w"
+// or "start_of_month+1m": an optional anchor followed by an optional offset.
+var relativeBound = regexp.MustCompile(`^(today|now|start_of_week|start_of_month)?\s*(?:([+-])\s*(\d+)\s*([dwmy]))?$`)
+
+// resolveBound turns a minDate/maxDate bound into a point in time for a
+// field of the given type. Bounds are either fixed ("2025-01-01", or "08:00"
+// for time fields) or relative to now: today, now, start_of_week (Monday),
+// start_of_month, each optionally shifted by ±N days, weeks, months or years.
+// A bound at day precision that is used as an upper limit covers the whole
+// day, so "maxDate": "today" accepts any time today.
+func resolveBound(fieldType, raw string, now time.Time, upper bool) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if t, err := parseTemporal(fieldType, raw); err == nil {
+		return t, true
+	}
+
+	var t time.Time
+	dayPrecision := true
+	if abs, ok := parseAnyTemporal(raw); ok {
+		t = abs
+		dayPrecision = len(raw) == len(dateLayout)
+	} else {
+		m := relativeBound.FindStringSubmatch(strings.ToLower(raw))
+		if m == nil || (m[1] == "" && m[2] == "") {
+			return time.Time{}, false
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		switch m[1] {
+		case "", "today":
+			t = today
+		case "now":
+			t = time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
+			dayPrecision = false
+		case "start_of_week":
+			offset := (int(today.Weekday()) + 6) % 7 // ISO weeks start on Monday
+			t = today.AddDate(0, 0, -offset)
+		case "start_of_month":
+			t = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+		if m[2] != "" {
+			n, _ := strconv.Atoi(m[3])
+			if m[2] == "-" {
+				n = -n
+			}
+			switch m[4] {
+			case "d":
+				t = t.AddDate(0, 0, n)
+			case "w":
+				t = t.AddDate(0, 0, 7*n)
+			case "m":
+				t = t.AddDate(0, n, 0)
+			case "y":
+				t = t.AddDate(n, 0, 0)
+			}
+		}
+	}
+
+	switch fieldType {
+	case "time":
+		// compare clock times only
+		t, _ = parseTemporal("time", t.Format(timeLayout))
+	case "datetime":
+		if upper && dayPrecision {
+			t = t.AddDate(0, 0, 1).Add(-time.Minute)
+		}
+	}
+	return t, true
+}
+
+// outOfBounds explains why a picked value lies outside the field's
+// minDate/maxDate range, resolved against now, or returns "" if it doesn't.
+func outOfBounds(fieldType, label string, v Validation, t time.Time, now time.Time) string {
+	if lo, ok := resolveBound(fieldType, v.MinDate, now, false); v.MinDate != "" && ok && t.Before(lo) {
+		return fmt.Sprintf("'%s' must be after %s", label, formatTemporal(fieldType, lo))
+	}
+	if hi, ok := resolveBound(fieldType, v.MaxDate, now, true); v.MaxDate != "" && ok && t.After(hi) {
+		return fmt.Sprintf("'%s' must be before %s", label, formatTemporal(fieldType, hi))
+	}
+	return ""
+}
+
+func temporalNoun(fieldType string) string {
+	switch fieldType {
+	case "time":
+		return "time"
+	case "datetime":
+		return "date and time"
+	default:
+		return "date"
+	}
+}
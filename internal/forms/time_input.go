@@ -0,0 +1,170 @@
+package forms
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// boundedPick keeps a picker inside its field's bounds: a pick that accept
+// refuses is undone, going back to the last accepted value.
+type boundedPick struct {
+	accept    func(value string) bool // nil accepts everything
+	last      string
+	reverting bool
+	setting   bool
+}
+
+// changed handles a pick of value and reports whether it stands; a refused
+// pick is undone with revert.
+func (b *boundedPick) changed(value string, revert func(string)) bool {
+	switch {
+	case b.reverting:
+		return false
+	case b.setting || b.accept == nil || b.accept(value):
+		b.last = value
+		return true
+	}
+	b.reverting = true
+	revert(b.last)
+	b.reverting = false
+	return false
+}
+
+// set applies a value given by the form, such as a draft's, without
+// checking it.
+func (b *boundedPick) set(value string, apply func(string)) {
+	b.setting = true
+	apply(value)
+	b.setting = false
+	b.last = value
+}
+
+// timeInput is the time field widget: hour and minute pickers.
+type timeInput struct {
+	hour, minute *widget.Select
+	content      fyne.CanvasObject
+	pick         boundedPick
+}
+
+// newTimeInput builds a time picker; accept, when set, refuses picks
+// outside the field's bounds.
+func newTimeInput(onChanged func(), accept func(string) bool) *timeInput {
+	hours := make([]string, 24)
+	for h := range hours {
+		hours[h] = fmt.Sprintf("%02d", h)
+	}
+	minutes := make([]string, 60)
+	for m := range minutes {
+		minutes[m] = fmt.Sprintf("%02d", m)
+	}
+
+	in := &timeInput{pick: boundedPick{accept: accept}}
+	picked := func(string) {
+		if in.pick.changed(in.Value(), in.apply) {
+			onChanged()
+		}
+	}
+	in.hour = widget.NewSelect(hours, picked)
+	in.minute = widget.NewSelect(minutes, picked)
+	in.hour.PlaceHolder = "HH"
+	in.minute.PlaceHolder = "MM"
+	in.content = container.NewHBox(in.hour, widget.NewLabel(":"), in.minute)
+	return in
+}
+
+// Value returns "15:04", or "" when nothing is picked. A half-picked time is
+// returned as-is so that validation reports it.
+func (in *timeInput) Value() string {
+	if in.hour.Selected == "" && in.minute.Selected == "" {
+		return ""
+	}
+	return in.hour.Selected + ":" + in.minute.Selected
+}
+
+func (in *timeInput) SetValue(raw string) {
+	if t, err := parseTemporal("time", raw); err == nil {
+		raw = t.Format(timeLayout)
+	} else {
+		raw = ""
+	}
+	in.pick.set(raw, in.apply)
+}
+
+// apply shows a value as Value returns it, half-picked ones included.
+func (in *timeInput) apply(raw string) {
+	hour, minute, _ := strings.Cut(raw, ":")
+	for _, p := range []struct {
+		s *widget.Select
+		v string
+	}{{in.hour, hour}, {in.minute, minute}} {
+		if p.v == "" {
+			p.s.ClearSelected()
+		} else {
+			p.s.SetSelected(p.v)
+		}
+	}
+}
+
+// dateTimeInput is the datetime field widget: a date picker and a time picker.
+type dateTimeInput struct {
+	date    *widget.DateEntry
+	clock   *timeInput
+	content fyne.CanvasObject
+	pick    boundedPick
+}
+
+// newDateTimeInput builds a date and time picker; accept, when set, refuses
+// picks outside the field's bounds.
+func newDateTimeInput(onChanged func(), accept func(string) bool) *dateTimeInput {
+	in := &dateTimeInput{
+		date: widget.NewDateEntry(),
+		pick: boundedPick{accept: accept},
+	}
+	picked := func() {
+		if in.pick.changed(in.Value(), in.apply) {
+			onChanged()
+		}
+	}
+	in.clock = newTimeInput(picked, nil)
+	in.date.OnChanged = func(*time.Time) { picked() }
+	in.content = container.NewBorder(nil, nil, nil, in.clock.content, in.date)
+	return in
+}
+
+// Value returns "2006-01-02T15:04", or "" when nothing is picked.
+func (in *dateTimeInput) Value() string {
+	clock := in.clock.Value()
+	if in.date.Date == nil {
+		if clock == "" {
+			return ""
+		}
+		return "T" + clock // incomplete: fails validation
+	}
+	return formatTemporal("date", *in.date.Date) + "T" + clock
+}
+
+func (in *dateTimeInput) SetValue(raw string) {
+	if t, err := parseTemporal("datetime", raw); err == nil {
+		raw = t.Format(dateTimeLayout)
+	} else {
+		raw = ""
+	}
+	in.pick.set(raw, in.apply)
+}
+
+// apply shows a value as Value returns it, half-picked ones included.
+func (in *dateTimeInput) apply(raw string) {
+	date, clock, _ := strings.Cut(raw, "T")
+	if d, err := parseTemporal("date", date); err == nil {
+		day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.Local)
+		in.date.SetDate(&day)
+	} else {
+		in.date.SetDate(nil)
+	}
+	in.clock.apply(clock)
+}
@@ -0,0 +1,300 @@
+package forms
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationResult holds what Validate finds in a form's values: the errors
+// and warnings of each field, keyed by field ID, in rule order. Fields of a
+// repeat instance are keyed like "patients[0].age", and a repeat section with
+// too few or too many entries has its error under its key, e.g. "patients".
+type ValidationResult struct {
+	Errors   map[string][]string `json:"errors,omitempty"`
+	Warnings map[string][]string `json:"warnings,omitempty"`
+}
+
+// Valid reports whether the values have no errors; warnings don't count.
+func (r ValidationResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Validate checks a form's values against its definition without any UI,
+// e.g. on a server or to re-check a queued draft. values holds answers as
+// widget text by field ID, with the fields of repeat instances keyed like
+// "patients[0].age"; PayloadValues turns a submission or draft payload into
+// this form. Calculated fields are recomputed, hidden sections and fields
+// are skipped, and messages are in the form's default locale. Attachment
+// files are not checked; ValidateFiles checks them too.
+func Validate(def FormDefinition, values map[string]string) ValidationResult {
+	return validateValues(def, values, nil, def.Locale(""))
+}
+
+// ValidateFiles is Validate that also checks the files of attachment fields,
+// found through files: that they exist, their size and their type.
+func ValidateFiles(def FormDefinition, values map[string]string, files AttachmentFiles) ValidationResult {
+	return validateValues(def, values, files, def.Locale(""))
+}
+
+// PayloadValues flattens a nested submission or draft payload into the
+// values Validate takes. Attachments of a submission are given by the name
+// of their multipart part, which is what ValidateFiles then looks up.
+func PayloadValues(data map[string]any) map[string]string {
+	return flattenValues(splitPayload(data))
+}
+
+// flattenValues merges the values of repeat instances, keyed by section key,
+// into the top-level ones as "key[n].field".
+func flattenValues(top map[string]string, repeats map[string][]map[string]string) map[string]string {
+	out := make(map[string]string, len(top))
+	for k, v := range top {
+		out[k] = v
+	}
+	for key, rows := range repeats {
+		for n, row := range rows {
+			for id, v := range row {
+				out[instanceKey(key, n)+id] = v
+			}
+		}
+	}
+	return out
+}
+
+var instanceField = regexp.MustCompile(`^(.+)\[(\d+)\]\.([^\[]+)$`)
+
+// instanceKey is the prefix of the fields of a repeat instance, e.g. "patients[0]."
+func instanceKey(key string, n int) string {
+	return fmt.Sprintf("%s[%d].", key, n)
+}
+
+// unflattenValues is the reverse of flattenValues. Repeat instances run up
+// to the highest index found, so an instance with no values is empty.
+func unflattenValues(values map[string]string) (map[string]string, map[string][]map[string]string) {
+	top := make(map[string]string)
+	repeats := make(map[string][]map[string]string)
+	for k, v := range values {
+		m := instanceField.FindStringSubmatch(k)
+		if m == nil {
+			top[k] = v
+			continue
+		}
+		n, _ := strconv.Atoi(m[2])
+		rows := repeats[m[1]]
+		for len(rows) <= n {
+			rows = append(rows, map[string]string{})
+		}
+		rows[n][m[3]] = v
+		repeats[m[1]] = rows
+	}
+	return top, repeats
+}
+
+// calculate evaluates calculated fields in form order, so a calculation may
+// build on an earlier one, and stores their results in values. A calculation
+// that fails leaves its field empty; formlint reports broken expressions.
+func calculate(fields []Field, values map[string]string) {
+	for _, f := range fields {
+		if f.Calculate == "" {
+			continue
+		}
+		text := ""
+		if result, err := evalExpression(f.Calculate, values); err == nil {
+			text = formatResult(result)
+		}
+		values[f.ID] = text
+	}
+}
+
+// validateValues is ValidateFiles with messages in locale; BuildForm checks
+// its widgets' values with it, and LocalFiles.
+func validateValues(def FormDefinition, values map[string]string, files AttachmentFiles, locale Locale) ValidationResult {
+	res := ValidationResult{Errors: map[string][]string{}, Warnings: map[string][]string{}}
+	add := func(prefix string, errs, warnings map[string][]string) {
+		for id, msgs := range errs {
+			res.Errors[prefix+id] = msgs
+		}
+		for id, msgs := range warnings {
+			res.Warnings[prefix+id] = msgs
+		}
+	}
+
+	top, repeats := unflattenValues(values)
+	var topFields []Field
+	for _, sec := range def.Sections {
+		if !sec.Repeat {
+			topFields = append(topFields, sec.Fields...)
+		}
+	}
+	calculate(topFields, top)
+	shownTop := relevantFields(def.Sections, top)
+	errs, warnings, _ := validateForm(shownTop, ruleValues(topFields, shownTop, top), files, locale)
+	add("", errs, warnings)
+
+	// Each repeat instance is validated on its own; its rules see the
+	// top-level values plus its own
+	for _, sec := range def.Sections {
+		if !sec.Repeat || !isRelevant(sec.Relevant, top) {
+			continue
+		}
+		rows := repeats[sec.Key()]
+		title := sec.Title.In(locale)
+		switch count := len(rows); {
+		case count < sec.MinRepeat:
+			res.Errors[sec.Key()] = []string{fmt.Sprintf("'%s' needs at least %d entries.", title, sec.MinRepeat)}
+		case sec.MaxRepeat > 0 && count > sec.MaxRepeat:
+			res.Errors[sec.Key()] = []string{fmt.Sprintf("'%s' allows at most %d entries.", title, sec.MaxRepeat)}
+		}
+		for n, row := range rows {
+			scoped := mergeValues(top, row)
+			calculate(sec.Fields, scoped)
+			shown := shownInstanceFields(sec, scoped)
+			values := ruleValues(slices.Concat(topFields, sec.Fields), slices.Concat(shownTop, shown), scoped)
+			errs, warnings, _ := validateForm(shown, values, files, locale)
+			add(instanceKey(sec.Key(), n), errs, warnings)
+		}
+	}
+	return res
+}
+
+// scope returns the messages of one scope, keyed by field ID: the top-level
+// fields for "", or a repeat instance for a prefix like "patients[0].".
+func (r ValidationResult) scope(prefix string) (errs, warnings map[string][]string) {
+	pick := func(msgs map[string][]string) map[string][]string {
+		out := make(map[string][]string)
+		for k, m := range msgs {
+			id, ok := strings.CutPrefix(k, prefix)
+			if ok && !strings.Contains(id, "[") {
+				out[id] = m
+			}
+		}
+		return out
+	}
+	return pick(r.Errors), pick(r.Warnings)
+}
+
+// ruleValues returns the values rules see: every field of the scope, with
+// the ones not shown counted as empty, like on submit.
+func ruleValues(scope, shown []Field, all map[string]string) map[string]string {
+	ids := make(map[string]bool, len(shown))
+	for _, f := range shown {
+		ids[f.ID] = true
+	}
+	values := make(map[string]string, len(scope))
+	for _, f := range scope {
+		if ids[f.ID] {
+			values[f.ID] = all[f.ID]
+		} else {
+			values[f.ID] = ""
+		}
+	}
+	return values
+}
+
+// validateForm applies validation rules to all field types, given the
+// values rules see (see ruleValues), and reports every rule that fails, in
+// order. Messages name fields by their label in locale. Rules that only warn
+// report warnings instead; a field with an error has no warnings. files finds
+// the files of attachment fields, which are not checked when it is nil.
+func validateForm(fields []Field, values map[string]string, files AttachmentFiles, locale Locale) (fieldErrors, fieldWarnings map[string][]string, err error) {
+	fieldErrors = make(map[string][]string)
+	fieldWarnings = make(map[string][]string)
+
+	for _, f := range fields {
+		v := f.Validation
+		val := values[f.ID]
+		label := f.Label.In(locale)
+		// format settings listed in Warnings only warn; allowNegative and
+		// decimalPlaces can't be, see warningRules
+		fail := func(setting, format string, args ...any) {
+			if warningRules[setting] && containsString(v.Warnings, setting) {
+				fieldWarnings[f.ID] = append(fieldWarnings[f.ID], fmt.Sprintf(format, args...))
+			} else {
+				fieldErrors[f.ID] = append(fieldErrors[f.ID], fmt.Sprintf(format, args...))
+			}
+		}
+		// unreadable values fail their format check and skip the rules
+		readable := true
+		unreadable := func(format string, args ...any) {
+			fieldErrors[f.ID] = append(fieldErrors[f.ID], fmt.Sprintf(format, args...))
+			readable = false
+		}
+
+		// ---------- Format ----------
+		switch {
+		case val == "":
+		case f.Type == "geopoint":
+			if _, err := parseGeoPoint(val); err != nil {
+				unreadable("'%s': %v", label, err)
+			}
+		case f.Type == "attachment" && files != nil:
+			if _, err := files.Lookup(val); err != nil {
+				unreadable("'%s': file is missing", label)
+			}
+		case isNumeric(f.Type):
+			num, err := strconv.ParseFloat(val, 64)
+			switch {
+			case err != nil:
+				unreadable("'%s' must be numeric", label)
+			case f.Type == "integer" && num != math.Trunc(num):
+				unreadable("'%s' must be a whole number", label)
+			default:
+				if !v.AllowNegative && num < 0 {
+					fail("allowNegative", "'%s' cannot be negative", label)
+				}
+				if v.DecimalPlaces > 0 && decimalPlaces(val) > v.DecimalPlaces {
+					fail("decimalPlaces", "'%s' allows at most %d decimal places", label, v.DecimalPlaces)
+				}
+			}
+		case isTemporal(f.Type):
+			if _, err := parseTemporal(f.Type, val); err != nil {
+				unreadable("'%s' is not a valid %s", label, temporalNoun(f.Type))
+			}
+		case f.Type == "period":
+			p, err := parsePeriod(val)
+			switch {
+			case err != nil:
+				unreadable("'%s' is not a valid period", label)
+			case f.PeriodType != "" && p.kind != f.PeriodType:
+				unreadable("'%s' must be a %s period", label, f.PeriodType)
+			case !v.AllowFuture && p.isFuture(time.Now()):
+				fail("allowFuture", "'%s' cannot be a future period", label)
+			}
+		}
+		if !readable {
+			continue
+		}
+
+		// ---------- Rules ----------
+		for _, r := range v.rules() {
+			msgs := r.check(f, val, values, files, locale)
+			if r.Warning {
+				fieldWarnings[f.ID] = append(fieldWarnings[f.ID], msgs...)
+			} else {
+				fieldErrors[f.ID] = append(fieldErrors[f.ID], msgs...)
+			}
+		}
+	}
+
+	for id, msgs := range fieldErrors {
+		if len(msgs) == 0 {
+			delete(fieldErrors, id)
+			continue
+		}
+		delete(fieldWarnings, id)
+	}
+	for id, msgs := range fieldWarnings {
+		if len(msgs) == 0 {
+			delete(fieldWarnings, id)
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return fieldErrors, fieldWarnings, fmt.Errorf("one or more fields are invalid")
+	}
+
+	return nil, fieldWarnings, nil
+}
@@ -0,0 +1,581 @@
+package forms
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testFiles are attachment files by value, for ValidateFiles.
+type testFiles map[string]AttachmentFile
+
+func (t testFiles) Lookup(value string) (AttachmentFile, error) {
+	if f, ok := t[value]; ok {
+		return f, nil
+	}
+	return AttachmentFile{}, errors.New("no such file")
+}
+
+func bound(n float64) *float64 { return &n }
+
+// oneSection is a form whose fields are labelled by their ID, so messages
+// read like "'age' is required".
+func oneSection(fields ...Field) FormDefinition {
+	for i := range fields {
+		fields[i].Label = Plain(fields[i].ID)
+	}
+	return FormDefinition{Sections: []Section{{Title: Plain("Main"), Fields: fields}}}
+}
+
+func TestValidate(t *testing.T) {
+	today := time.Now()
+	day := func(days int) string { return today.AddDate(0, 0, days).Format(dateLayout) }
+	month := func(months int) string {
+		return periodOf("monthly", time.Date(today.Year(), today.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)).ID()
+	}
+	pdf := testFiles{"report.pdf": {Name: "report.pdf", Size: 2048}}
+
+	patients := FormDefinition{Sections: []Section{{
+		ID: "patients", Title: Plain("Patients"), Repeat: true, MinRepeat: 1, MaxRepeat: 2,
+		Fields: []Field{{ID: "age", Label: Plain("age"), Type: "integer", Validation: Validation{Required: true}}},
+	}}}
+
+	tests := []struct {
+		name     string
+		def      FormDefinition
+		values   map[string]string
+		files    AttachmentFiles
+		errors   map[string][]string
+		warnings map[string][]string
+	}{
+		// ---------- Required ----------
+		{
+			name:   "required text",
+			def:    oneSection(Field{ID: "name", Type: "text", Validation: Validation{Required: true}}),
+			values: map[string]string{"name": ""},
+			errors: map[string][]string{"name": {"'name' is required"}},
+		},
+		{
+			name:   "required text filled in",
+			def:    oneSection(Field{ID: "name", Type: "text", Validation: Validation{Required: true}}),
+			values: map[string]string{"name": "Amina"},
+		},
+		{
+			name:   "required boolean unchecked",
+			def:    oneSection(Field{ID: "consent", Type: "boolean", Validation: Validation{Required: true}}),
+			values: map[string]string{"consent": "false"},
+			errors: map[string][]string{"consent": {"'consent' must be checked to proceed"}},
+		},
+		{
+			name:   "required boolean unset",
+			def:    oneSection(Field{ID: "consent", Type: "boolean", Validation: Validation{Required: true}}),
+			values: map[string]string{},
+			errors: map[string][]string{"consent": {"'consent' must be checked to proceed"}},
+		},
+		{
+			name:   "required boolean checked",
+			def:    oneSection(Field{ID: "consent", Type: "boolean", Validation: Validation{Required: true}}),
+			values: map[string]string{"consent": "true"},
+		},
+		{
+			name:   "required signature",
+			def:    oneSection(Field{ID: "sign", Type: "signature", Validation: Validation{Required: true}}),
+			values: map[string]string{"sign": ""},
+			errors: map[string][]string{"sign": {"'sign' must be signed"}},
+		},
+
+		// ---------- Length, range and format ----------
+		{
+			name:   "minLength",
+			def:    oneSection(Field{ID: "name", Type: "text", Validation: Validation{MinLength: 3}}),
+			values: map[string]string{"name": "Al"},
+			errors: map[string][]string{"name": {"'name' must be at least 3 characters"}},
+		},
+		{
+			name:   "maxLength",
+			def:    oneSection(Field{ID: "name", Type: "text", Validation: Validation{MaxLength: 3}}),
+			values: map[string]string{"name": "Amina"},
+			errors: map[string][]string{"name": {"'name' must be no more than 3 characters"}},
+		},
+		{
+			name:   "length not checked when empty",
+			def:    oneSection(Field{ID: "name", Type: "text", Validation: Validation{MinLength: 3}}),
+			values: map[string]string{"name": ""},
+		},
+		{
+			name:   "min",
+			def:    oneSection(Field{ID: "age", Type: "integer", Validation: Validation{Min: bound(18)}}),
+			values: map[string]string{"age": "12"},
+			errors: map[string][]string{"age": {"'age' must be at least 18"}},
+		},
+		{
+			name:   "max",
+			def:    oneSection(Field{ID: "age", Type: "integer", Validation: Validation{Max: bound(120)}}),
+			values: map[string]string{"age": "130"},
+			errors: map[string][]string{"age": {"'age' must be at most 120"}},
+		},
+		{
+			name:   "min of zero is a bound",
+			def:    oneSection(Field{ID: "temp", Type: "decimal", Validation: Validation{Min: bound(0), AllowNegative: true}}),
+			values: map[string]string{"temp": "-0.5"},
+			errors: map[string][]string{"temp": {"'temp' must be at least 0"}},
+		},
+		{
+			name:   "not a number skips the rules",
+			def:    oneSection(Field{ID: "age", Type: "integer", Validation: Validation{Max: bound(120)}}),
+			values: map[string]string{"age": "old"},
+			errors: map[string][]string{"age": {"'age' must be numeric"}},
+		},
+		{
+			name:   "pattern",
+			def:    oneSection(Field{ID: "code", Type: "text", Validation: Validation{Pattern: `^[A-Z]{3}$`}}),
+			values: map[string]string{"code": "ab1"},
+			errors: map[string][]string{"code": {"'code' does not match expected format"}},
+		},
+		{
+			name:   "relative minDate",
+			def:    oneSection(Field{ID: "onset", Type: "date", Validation: Validation{MinDate: "-7d"}}),
+			values: map[string]string{"onset": day(-10)},
+			errors: map[string][]string{"onset": {"'onset' must be after " + day(-7)}},
+		},
+		{
+			name:   "relative minDate met",
+			def:    oneSection(Field{ID: "onset", Type: "date", Validation: Validation{MinDate: "-7d"}}),
+			values: map[string]string{"onset": day(-7)},
+		},
+		{
+			name:   "relative maxDate",
+			def:    oneSection(Field{ID: "onset", Type: "date", Validation: Validation{MaxDate: "today"}}),
+			values: map[string]string{"onset": day(1)},
+			errors: map[string][]string{"onset": {"'onset' must be before " + day(0)}},
+		},
+		{
+			name:   "minSelected",
+			def:    oneSection(Field{ID: "symptoms", Type: "select_multiple", Validation: Validation{MinSelected: 2}}),
+			values: map[string]string{"symptoms": "fever"},
+			errors: map[string][]string{"symptoms": {"'symptoms' needs at least 2 selections"}},
+		},
+		{
+			name:   "maxSelected",
+			def:    oneSection(Field{ID: "symptoms", Type: "select_multiple", Validation: Validation{MaxSelected: 1}}),
+			values: map[string]string{"symptoms": joinList([]string{"fever", "cough"})},
+			errors: map[string][]string{"symptoms": {"'symptoms' allows at most 1 selections"}},
+		},
+
+		// ---------- Cross-field ----------
+		{
+			name: "greaterThanField",
+			def: oneSection(
+				Field{ID: "cases", Type: "integer", Validation: Validation{GreaterThanField: "deaths"}},
+				Field{ID: "deaths", Type: "integer"}),
+			values: map[string]string{"cases": "2", "deaths": "5"},
+			errors: map[string][]string{"cases": {"'cases' must be greater than 'deaths'"}},
+		},
+		{
+			name: "lessThanField",
+			def: oneSection(
+				Field{ID: "deaths", Type: "integer", Validation: Validation{LessThanField: "cases"}},
+				Field{ID: "cases", Type: "integer"}),
+			values: map[string]string{"cases": "5", "deaths": "5"},
+			errors: map[string][]string{"deaths": {"'deaths' must be less than 'cases'"}},
+		},
+		{
+			name: "beforeField",
+			def: oneSection(
+				Field{ID: "onset", Type: "date", Validation: Validation{BeforeField: "visit"}},
+				Field{ID: "visit", Type: "date"}),
+			values: map[string]string{"onset": "2024-03-05", "visit": "2024-03-01"},
+			errors: map[string][]string{"onset": {"'onset' must be before 'visit'"}},
+		},
+		{
+			name: "afterField",
+			def: oneSection(
+				Field{ID: "visit", Type: "date", Validation: Validation{AfterField: "onset"}},
+				Field{ID: "onset", Type: "date"}),
+			values: map[string]string{"onset": "2024-03-05", "visit": "2024-03-05"},
+			errors: map[string][]string{"visit": {"'visit' must be after 'onset'"}},
+		},
+		{
+			name: "equalToField",
+			def: oneSection(
+				Field{ID: "confirm", Type: "text", Validation: Validation{EqualToField: "email"}},
+				Field{ID: "email", Type: "text"}),
+			values: map[string]string{"email": "a@example.org", "confirm": "b@example.org"},
+			errors: map[string][]string{"confirm": {"'confirm' must equal 'email'"}},
+		},
+		{
+			name: "notEqualToField",
+			def: oneSection(
+				Field{ID: "alt_phone", Type: "text", Validation: Validation{NotEqualToField: "phone"}},
+				Field{ID: "phone", Type: "text"}),
+			values: map[string]string{"phone": "0700", "alt_phone": "0700"},
+			errors: map[string][]string{"alt_phone": {"'alt_phone' must not equal 'phone'"}},
+		},
+		{
+			name: "cross-field skipped while the other is empty",
+			def: oneSection(
+				Field{ID: "cases", Type: "integer", Validation: Validation{GreaterThanField: "deaths"}},
+				Field{ID: "deaths", Type: "integer"}),
+			values: map[string]string{"cases": "2"},
+		},
+		{
+			name: "cross-field against a hidden field",
+			def: oneSection(
+				Field{ID: "confirm", Type: "text", Validation: Validation{EqualToField: "email"}},
+				Field{ID: "email", Type: "text", Relevant: "false"}),
+			values: map[string]string{"email": "a@example.org", "confirm": "b@example.org"},
+		},
+		{
+			name: "formula",
+			def: oneSection(
+				Field{ID: "a", Type: "integer"},
+				Field{ID: "b", Type: "integer"},
+				Field{ID: "total", Type: "integer", Validation: Validation{Formula: "total == a + b"}}),
+			values: map[string]string{"a": "1", "b": "2", "total": "4"},
+			errors: map[string][]string{"total": {"Formula validation failed for 'total'"}},
+		},
+		{
+			name: "formula met",
+			def: oneSection(
+				Field{ID: "a", Type: "integer"},
+				Field{ID: "b", Type: "integer"},
+				Field{ID: "total", Type: "integer", Validation: Validation{Formula: "total == a + b"}}),
+			values: map[string]string{"a": "1", "b": "2", "total": "3"},
+		},
+
+		// ---------- Attachments ----------
+		{
+			name:   "maxSize",
+			def:    oneSection(Field{ID: "doc", Type: "attachment", Validation: Validation{MaxSize: 1024}}),
+			values: map[string]string{"doc": "report.pdf"},
+			files:  pdf,
+			errors: map[string][]string{"doc": {"'doc': file is larger than 1.0 KB"}},
+		},
+		{
+			name:   "allowedTypes",
+			def:    oneSection(Field{ID: "doc", Type: "attachment", Validation: Validation{AllowedTypes: []string{"image/*"}}}),
+			values: map[string]string{"doc": "report.pdf"},
+			files:  pdf,
+			errors: map[string][]string{"doc": {"'doc': file type application/pdf is not allowed"}},
+		},
+		{
+			name:   "allowedTypes met",
+			def:    oneSection(Field{ID: "doc", Type: "attachment", Validation: Validation{AllowedTypes: []string{"application/pdf"}}}),
+			values: map[string]string{"doc": "report.pdf"},
+			files:  pdf,
+		},
+		{
+			name:   "missing file",
+			def:    oneSection(Field{ID: "doc", Type: "attachment", Validation: Validation{MaxSize: 1024}}),
+			values: map[string]string{"doc": "scan.png"},
+			files:  pdf,
+			errors: map[string][]string{"doc": {"'doc': file is missing"}},
+		},
+		{
+			name:   "files not checked without a lookup",
+			def:    oneSection(Field{ID: "doc", Type: "attachment", Validation: Validation{Required: true, MaxSize: 1024}}),
+			values: map[string]string{"doc": "report.pdf"},
+		},
+
+		// ---------- Number format ----------
+		{
+			name:   "decimalPlaces",
+			def:    oneSection(Field{ID: "weight", Type: "decimal", Validation: Validation{DecimalPlaces: 2}}),
+			values: map[string]string{"weight": "3.125"},
+			errors: map[string][]string{"weight": {"'weight' allows at most 2 decimal places"}},
+		},
+		{
+			name:   "decimalPlaces at the limit",
+			def:    oneSection(Field{ID: "weight", Type: "decimal", Validation: Validation{DecimalPlaces: 2}}),
+			values: map[string]string{"weight": "3.12"},
+		},
+		{
+			name:   "integer with a fraction",
+			def:    oneSection(Field{ID: "children", Type: "integer"}),
+			values: map[string]string{"children": "2.5"},
+			errors: map[string][]string{"children": {"'children' must be a whole number"}},
+		},
+		{
+			name:   "integer written with a zero fraction",
+			def:    oneSection(Field{ID: "children", Type: "integer"}),
+			values: map[string]string{"children": "2.0"},
+		},
+		{
+			name:   "negative number",
+			def:    oneSection(Field{ID: "cases", Type: "number"}),
+			values: map[string]string{"cases": "-1"},
+			errors: map[string][]string{"cases": {"'cases' cannot be negative"}},
+		},
+		{
+			name:   "zero is not negative",
+			def:    oneSection(Field{ID: "cases", Type: "number"}),
+			values: map[string]string{"cases": "0"},
+		},
+
+		// ---------- Geopoints ----------
+		{
+			name:   "geopoint at the edges of the map",
+			def:    oneSection(Field{ID: "home", Type: "geopoint"}),
+			values: map[string]string{"home": "-90 180 0"},
+		},
+		{
+			name:   "geopoint latitude out of range",
+			def:    oneSection(Field{ID: "home", Type: "geopoint"}),
+			values: map[string]string{"home": "90.5 32.6"},
+			errors: map[string][]string{"home": {"'home': latitude must be between -90 and 90"}},
+		},
+		{
+			name:   "geopoint longitude out of range",
+			def:    oneSection(Field{ID: "home", Type: "geopoint"}),
+			values: map[string]string{"home": "2.7 -180.5"},
+			errors: map[string][]string{"home": {"'home': longitude must be between -180 and 180"}},
+		},
+		{
+			name:   "geopoint with negative accuracy",
+			def:    oneSection(Field{ID: "home", Type: "geopoint"}),
+			values: map[string]string{"home": "2.7 32.6 -1"},
+			errors: map[string][]string{"home": {"'home': accuracy cannot be negative"}},
+		},
+		{
+			name:   "geopoint without a longitude",
+			def:    oneSection(Field{ID: "home", Type: "geopoint"}),
+			values: map[string]string{"home": "2.7"},
+			errors: map[string][]string{"home": {"'home': expected latitude and longitude"}},
+		},
+
+		// ---------- Periods ----------
+		{
+			name:   "current period",
+			def:    oneSection(Field{ID: "month", Type: "period", PeriodType: "monthly"}),
+			values: map[string]string{"month": month(0)},
+		},
+		{
+			name:   "future period",
+			def:    oneSection(Field{ID: "month", Type: "period", PeriodType: "monthly"}),
+			values: map[string]string{"month": month(1)},
+			errors: map[string][]string{"month": {"'month' cannot be a future period"}},
+		},
+		{
+			name:   "future period allowed",
+			def:    oneSection(Field{ID: "month", Type: "period", PeriodType: "monthly", Validation: Validation{AllowFuture: true}}),
+			values: map[string]string{"month": month(1)},
+		},
+		{
+			name:     "future period only warned about",
+			def:      oneSection(Field{ID: "month", Type: "period", PeriodType: "monthly", Validation: Validation{Warnings: []string{"allowFuture"}}}),
+			values:   map[string]string{"month": month(1)},
+			warnings: map[string][]string{"month": {"'month' cannot be a future period"}},
+		},
+		{
+			name:   "period of another type",
+			def:    oneSection(Field{ID: "month", Type: "period", PeriodType: "monthly"}),
+			values: map[string]string{"month": "2024Q1"},
+			errors: map[string][]string{"month": {"'month' must be a monthly period"}},
+		},
+		{
+			name:   "not a period",
+			def:    oneSection(Field{ID: "month", Type: "period"}),
+			values: map[string]string{"month": "March"},
+			errors: map[string][]string{"month": {"'month' is not a valid period"}},
+		},
+
+		// ---------- Times ----------
+		{
+			name:   "time before minDate",
+			def:    oneSection(Field{ID: "opened", Type: "time", Validation: Validation{MinDate: "08:00"}}),
+			values: map[string]string{"opened": "07:59"},
+			errors: map[string][]string{"opened": {"'opened' must be after 08:00"}},
+		},
+		{
+			name:   "time at minDate",
+			def:    oneSection(Field{ID: "opened", Type: "time", Validation: Validation{MinDate: "08:00"}}),
+			values: map[string]string{"opened": "08:00"},
+		},
+		{
+			name:   "time at maxDate",
+			def:    oneSection(Field{ID: "closed", Type: "time", Validation: Validation{MaxDate: "17:00"}}),
+			values: map[string]string{"closed": "17:00"},
+		},
+		{
+			name:   "time after maxDate",
+			def:    oneSection(Field{ID: "closed", Type: "time", Validation: Validation{MaxDate: "17:00"}}),
+			values: map[string]string{"closed": "17:01"},
+			errors: map[string][]string{"closed": {"'closed' must be before 17:00"}},
+		},
+		{
+			name:   "not a time",
+			def:    oneSection(Field{ID: "opened", Type: "time"}),
+			values: map[string]string{"opened": "25:00"},
+			errors: map[string][]string{"opened": {"'opened' is not a valid time"}},
+		},
+		{
+			name:   "datetime before minDate",
+			def:    oneSection(Field{ID: "seen", Type: "datetime", Validation: Validation{MinDate: "2024-03-01T08:00"}}),
+			values: map[string]string{"seen": "2024-03-01T07:59"},
+			errors: map[string][]string{"seen": {"'seen' must be after 2024-03-01T08:00"}},
+		},
+		{
+			name:   "datetime on the last day of maxDate",
+			def:    oneSection(Field{ID: "seen", Type: "datetime", Validation: Validation{MaxDate: "2024-03-10"}}),
+			values: map[string]string{"seen": "2024-03-10T23:59"},
+		},
+		{
+			name:   "datetime after maxDate",
+			def:    oneSection(Field{ID: "seen", Type: "datetime", Validation: Validation{MaxDate: "2024-03-10"}}),
+			values: map[string]string{"seen": "2024-03-11T00:00"},
+			errors: map[string][]string{"seen": {"'seen' must be before 2024-03-10T23:59"}},
+		},
+
+		// ---------- Calculations and relevance ----------
+		{
+			name: "calculated value checked",
+			def: oneSection(
+				Field{ID: "a", Type: "integer"},
+				Field{ID: "b", Type: "integer"},
+				Field{ID: "total", Type: "integer", Calculate: "a + b", Validation: Validation{Max: bound(10)}}),
+			values: map[string]string{"a": "6", "b": "5", "total": "3"},
+			errors: map[string][]string{"total": {"'total' must be at most 10"}},
+		},
+		{
+			name: "calculated value at the limit",
+			def: oneSection(
+				Field{ID: "a", Type: "integer"},
+				Field{ID: "b", Type: "integer"},
+				Field{ID: "total", Type: "integer", Calculate: "a + b", Validation: Validation{Max: bound(10)}}),
+			values: map[string]string{"a": "5", "b": "5"},
+		},
+		{
+			name: "hidden field skipped",
+			def: oneSection(
+				Field{ID: "age", Type: "integer"},
+				Field{ID: "occupation", Type: "text", Relevant: "age >= 18", Validation: Validation{Required: true}}),
+			values: map[string]string{"age": "17"},
+		},
+		{
+			name: "field shown at the boundary",
+			def: oneSection(
+				Field{ID: "age", Type: "integer"},
+				Field{ID: "occupation", Type: "text", Relevant: "age >= 18", Validation: Validation{Required: true}}),
+			values: map[string]string{"age": "18"},
+			errors: map[string][]string{"occupation": {"'occupation' is required"}},
+		},
+		{
+			name: "hidden section skipped",
+			def: FormDefinition{Sections: []Section{
+				{Title: Plain("Main"), Fields: []Field{{ID: "died", Label: Plain("died"), Type: "boolean"}}},
+				{Title: Plain("Death"), Relevant: "died == true", Fields: []Field{
+					{ID: "cause", Label: Plain("cause"), Type: "text", Validation: Validation{Required: true}},
+				}},
+			}},
+			values: map[string]string{"died": "false"},
+		},
+		{
+			name: "shown section checked",
+			def: FormDefinition{Sections: []Section{
+				{Title: Plain("Main"), Fields: []Field{{ID: "died", Label: Plain("died"), Type: "boolean"}}},
+				{Title: Plain("Death"), Relevant: "died == true", Fields: []Field{
+					{ID: "cause", Label: Plain("cause"), Type: "text", Validation: Validation{Required: true}},
+				}},
+			}},
+			values: map[string]string{"died": "true"},
+			errors: map[string][]string{"cause": {"'cause' is required"}},
+		},
+
+		// ---------- Warnings ----------
+		{
+			name:     "setting listed in warnings",
+			def:      oneSection(Field{ID: "age", Type: "integer", Validation: Validation{Max: bound(100), Warnings: []string{"max"}}}),
+			values:   map[string]string{"age": "105"},
+			warnings: map[string][]string{"age": {"'age' must be at most 100"}},
+		},
+		{
+			name: "warning rule with its own message",
+			def: oneSection(Field{ID: "age", Type: "integer", Validation: Validation{Rules: []Rule{
+				{Max: bound(100), Message: Plain("Over 100: please double-check"), Warning: true},
+			}}}),
+			values:   map[string]string{"age": "105"},
+			warnings: map[string][]string{"age": {"Over 100: please double-check"}},
+		},
+		{
+			name: "errors hide warnings",
+			def: oneSection(Field{ID: "age", Type: "integer", Validation: Validation{Rules: []Rule{
+				{Max: bound(120)},
+				{Max: bound(100), Warning: true},
+			}}}),
+			values: map[string]string{"age": "130"},
+			errors: map[string][]string{"age": {"'age' must be at most 120"}},
+		},
+		{
+			name:   "allowNegative can't be a warning",
+			def:    oneSection(Field{ID: "cases", Type: "number", Validation: Validation{Warnings: []string{"allowNegative"}}}),
+			values: map[string]string{"cases": "-1"},
+			errors: map[string][]string{"cases": {"'cases' cannot be negative"}},
+		},
+
+		// ---------- Repeats ----------
+		{
+			name:   "too few entries",
+			def:    patients,
+			values: map[string]string{},
+			errors: map[string][]string{"patients": {"'Patients' needs at least 1 entries."}},
+		},
+		{
+			name:   "too many entries",
+			def:    patients,
+			values: map[string]string{"patients[0].age": "1", "patients[1].age": "2", "patients[2].age": "3"},
+			errors: map[string][]string{"patients": {"'Patients' allows at most 2 entries."}},
+		},
+		{
+			name:   "instance fields keyed by instance",
+			def:    patients,
+			values: map[string]string{"patients[0].age": "40", "patients[1].age": ""},
+			errors: map[string][]string{"patients[1].age": {"'age' is required"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := ValidateFiles(tc.def, tc.values, tc.files)
+			if !sameMessages(res.Errors, tc.errors) {
+				t.Errorf("errors = %q, want %q", res.Errors, tc.errors)
+			}
+			if !sameMessages(res.Warnings, tc.warnings) {
+				t.Errorf("warnings = %q, want %q", res.Warnings, tc.warnings)
+			}
+			if res.Valid() != (len(tc.errors) == 0) {
+				t.Errorf("Valid() = %v with errors %q", res.Valid(), res.Errors)
+			}
+		})
+	}
+}
+
+// sameMessages compares messages by field; nil and empty are the same.
+func sameMessages(got, want map[string][]string) bool {
+	if len(got) == 0 && len(want) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func TestPayloadValues(t *testing.T) {
+	var parts []attachmentPart
+	payload := stripAttachments(map[string]any{
+		"consent": false,
+		"doc":     map[string]any{"name": "report.pdf", "mimeType": "application/pdf", "size": 2048, "path": "/data/report.pdf"},
+		"patients": []map[string]any{
+			{"age": 40, "xray": map[string]any{"name": "chest.png", "path": "/data/chest.png"}},
+		},
+	}, "", &parts)
+
+	got := PayloadValues(payload)
+	want := map[string]string{
+		"consent":          "false",
+		"doc":              "doc",
+		"patients[0].age":  "40",
+		"patients[0].xray": "patients[0].xray",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PayloadValues = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package forms
+
+import (
+	"strconv"
+	"strings"
+)
+
+// listSeparator joins the choices of a select_multiple field in widget text
+// and expression parameters; payloads and drafts carry a JSON list instead.
+const listSeparator = "\n"
+
+func joinList(items []string) string {
+	return strings.Join(items, listSeparator)
+}
+
+func splitList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, listSeparator)
+}
+
+// typedValue converts the raw text of a field into the value sent to the
+// backend and stored in drafts. Numbers become integers or decimals and
+// booleans become true/false. Dates and times stay ISO 8601 strings, and
+// multi-selects become lists. Geo-points become {latitude, longitude,
+// accuracy} objects, and attachments and signatures become {name, mimeType,
+// size, path} objects. Empty values become null. Text that doesn't parse for
+// its type is kept as-is.
+func typedValue(f Field, raw string) any {
+	if raw == "" {
+		return nil
+	}
+	switch f.Type {
+	case "number":
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n
+		}
+		if x, err := strconv.ParseFloat(raw, 64); err == nil {
+			return x
+		}
+	case "integer":
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n
+		}
+	case "decimal":
+		if x, err := strconv.ParseFloat(raw, 64); err == nil {
+			return x
+		}
+	case "boolean":
+		return raw == "true" || raw == "1" || strings.EqualFold(raw, "yes")
+	case "select_multiple":
+		return splitList(raw)
+	case "attachment", "signature":
+		return attachmentValue(raw)
+	case "geopoint":
+		if p, err := parseGeoPoint(raw); err == nil {
+			return p.toMap()
+		}
+	case "date", "time", "datetime":
+		if t, err := parseTemporal(f.Type, raw); err == nil {
+			return formatTemporal(f.Type, t)
+		}
+	}
+	return raw
+}
+
+// typedValues converts the raw values of the given fields.
+func typedValues(fields []Field, raw map[string]string) map[string]any {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if v, ok := raw[f.ID]; ok {
+			out[f.ID] = typedValue(f, v)
+		}
+	}
+	return out
+}
+
+// rawValue turns a typed (or legacy string) payload value back into widget text.
+func rawValue(v any) string {
+	switch val := v.(type) {
+	case []string:
+		return joinList(val)
+	case []any:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			items = append(items, formatResult(item))
+		}
+		return joinList(items)
+	case map[string]any:
+		if path, ok := isAttachmentValue(val); ok {
+			return path
+		}
+		// a submitted attachment names its multipart part instead of a path
+		if part, ok := val["part"].(string); ok && part != "" {
+			return part
+		}
+		if p, ok := geoPointFromMap(val); ok {
+			return p.String()
+		}
+		return ""
+	default:
+		return formatResult(v)
+	}
+}
+
+// retypeDraft converts the plain string values of drafts saved before
+// payloads were typed, in place. Typed values are left alone; strings go
+// through typedValue, which keeps typed dates and text as they are.
+func retypeDraft(def FormDefinition, data map[string]any) {
+	retype := func(fields []Field, values map[string]any) {
+		for _, f := range fields {
+			if s, ok := values[f.ID].(string); ok {
+				values[f.ID] = typedValue(f, s)
+			}
+		}
+	}
+	for _, sec := range def.Sections {
+		if !sec.Repeat {
+			retype(sec.Fields, data)
+			continue
+		}
+		rows, _ := data[sec.Key()].([]any)
+		for _, row := range rows {
+			if m, ok := row.(map[string]any); ok {
+				retype(sec.Fields, m)
+			}
+		}
+	}
+}
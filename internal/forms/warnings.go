@@ -0,0 +1,54 @@
+package forms
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// warningRules are the rules Validation.Warnings may list, by JSON name.
+// Checks of the value's format, such as a number that doesn't parse, are
+// always errors, and so are allowNegative and decimalPlaces, which number
+// inputs enforce as the user types.
+var warningRules = map[string]bool{
+	"required": true, "minLength": true, "maxLength": true,
+	"min": true, "max": true, "pattern": true,
+	"minDate": true, "maxDate": true, "allowFuture": true,
+	"greaterThanField": true, "lessThanField": true,
+	"beforeField": true, "afterField": true,
+	"equalToField": true, "notEqualToField": true,
+	"minSelected": true, "maxSelected": true,
+	"maxSize": true, "allowedTypes": true,
+	"formula": true,
+}
+
+// warningsKey holds the warnings the user confirmed in a submission's
+// payload; SubmitForm sends them next to the data rather than in it.
+const warningsKey = "_warnings"
+
+// warning is a plausibility check that failed and was confirmed on submit.
+type warning struct {
+	Field   string `json:"field"` // field ID, e.g. "cases" or "patients[0].age"
+	Message string `json:"message"`
+}
+
+// confirmWarnings lists the warnings and calls onConfirm if the user
+// submits anyway.
+func confirmWarnings(warnings []warning, win fyne.Window, onConfirm func()) {
+	lines := make([]string, len(warnings))
+	for i, w := range warnings {
+		lines[i] = "• " + w.Message
+	}
+	msg := widget.NewLabel("Some answers look unusual:\n\n" + strings.Join(lines, "\n") +
+		"\n\nPlease check them. Submit anyway?")
+	msg.Wrapping = fyne.TextWrapWord
+	d := dialog.NewCustomConfirm("Please confirm", "Submit anyway", "Go back", msg, func(ok bool) {
+		if ok {
+			onConfirm()
+		}
+	}, win)
+	d.Resize(fyne.NewSize(400, 0))
+	d.Show()
+}
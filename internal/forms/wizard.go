@@ -0,0 +1,181 @@
+package forms
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// wizardPages is what a wizard needs from BuildForm: the content of each
+// section, the current top-level values for relevance, the check run before
+// leaving a section, and the answers of a section for the review page.
+type wizardPages struct {
+	sections []fyne.CanvasObject
+	values   func() map[string]string
+	check    func(section int) bool
+	review   func(section int) fyne.CanvasObject
+}
+
+// buildWizard shows a form one section per page, with a progress bar,
+// Previous/Next buttons and a final review page holding the submit button.
+// Sections hidden by their relevance are skipped. It returns the content and
+// the button bar.
+func buildWizard(def FormDefinition, locale Locale, p wizardPages, submit, save *widget.Button) (fyne.CanvasObject, *fyne.Container) {
+	sections := def.Sections
+	review := len(sections) // page index of the review page
+	current := 0
+
+	page := container.NewStack()
+	title := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	progress := widget.NewProgressBar()
+	progress.TextFormatter = func() string {
+		return fmt.Sprintf("Step %.0f of %.0f", progress.Value, progress.Max)
+	}
+	prev := widget.NewButtonWithIcon("Previous", theme.NavigateBackIcon(), nil)
+	next := widget.NewButtonWithIcon("Next", theme.NavigateNextIcon(), nil)
+	next.IconPlacement = widget.ButtonIconTrailingText
+
+	// step returns the next shown page from i in direction dir: -1 before
+	// the first section, or the review page, which is always shown.
+	step := func(i, dir int) int {
+		values := p.values()
+		for i += dir; i >= 0 && i < review; i += dir {
+			if isRelevant(sections[i].Relevant, values) {
+				return i
+			}
+		}
+		return i
+	}
+
+	var showPage func(i int)
+	reviewPage := func() fyne.CanvasObject {
+		values := p.values()
+		box := container.NewVBox()
+		for i, sec := range sections {
+			if !isRelevant(sec.Relevant, values) {
+				continue
+			}
+			edit := widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), func() { showPage(i) })
+			edit.Importance = widget.LowImportance
+			heading := widget.NewLabelWithStyle(sec.Title.In(locale), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			box.Add(container.NewBorder(nil, nil, nil, edit, heading))
+			box.Add(p.review(i))
+			box.Add(widget.NewSeparator())
+		}
+		return box
+	}
+
+	showPage = func(i int) {
+		current = i
+		values := p.values()
+		steps, at := 1, 1
+		for j, sec := range sections {
+			if isRelevant(sec.Relevant, values) {
+				steps++
+				if j < i {
+					at++
+				}
+			}
+		}
+		if i == review {
+			title.SetText("Review")
+			page.Objects = []fyne.CanvasObject{reviewPage()}
+			next.Hide()
+			submit.Show()
+		} else {
+			title.SetText(sections[i].Title.In(locale))
+			page.Objects = []fyne.CanvasObject{p.sections[i]}
+			submit.Hide()
+			next.Show()
+		}
+		page.Refresh()
+		progress.Max = float64(steps)
+		progress.SetValue(float64(at))
+		if step(i, -1) < 0 {
+			prev.Disable()
+		} else {
+			prev.Enable()
+		}
+	}
+
+	next.OnTapped = func() {
+		if p.check(current) {
+			showPage(step(current, 1))
+		}
+	}
+	prev.OnTapped = func() {
+		showPage(step(current, -1))
+	}
+	// answers can change after their page was checked, e.g. through Edit,
+	// so check every page again and go back to the first one that fails
+	send := submit.OnTapped
+	submit.OnTapped = func() {
+		for i := step(-1, 1); i < review; i = step(i, 1) {
+			if !p.check(i) {
+				showPage(i)
+				return
+			}
+		}
+		send()
+	}
+
+	showPage(step(-1, 1))
+	buttons := container.NewGridWithColumns(3, prev, container.NewStack(next, submit), save)
+	return container.NewVBox(progress, title, page), buttons
+}
+
+// reviewFields lists the shown fields of one scope with their answers.
+func reviewFields(fields []Field, ws *fieldWidgets, values map[string]string, locale Locale) fyne.CanvasObject {
+	form := widget.NewForm()
+	for _, f := range fields {
+		if !isRelevant(f.Relevant, values) {
+			continue
+		}
+		answer := widget.NewLabel(reviewText(f, ws.options[f.ID], values[f.ID]))
+		answer.Wrapping = fyne.TextWrapWord
+		form.Append(f.Label.In(locale), answer)
+	}
+	return form
+}
+
+// reviewRepeat lists the answers of each instance of a repeat section.
+func reviewRepeat(sec Section, insts []*repeatInstance, values map[string]string, locale Locale) fyne.CanvasObject {
+	box := container.NewVBox()
+	if len(insts) == 0 {
+		box.Add(widget.NewLabel("No entries"))
+	}
+	for n, inst := range insts {
+		title := fmt.Sprintf("%s #%d", sec.Title.In(locale), n+1)
+		box.Add(widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Italic: true}))
+		box.Add(reviewFields(sec.Fields, inst.ws, mergeValues(values, inst.ws.values()), locale))
+	}
+	return box
+}
+
+// reviewText returns an answer as the review page shows it: options by
+// their label, and files by name.
+func reviewText(f Field, opts optionLabels, value string) string {
+	if value == "" {
+		return "—"
+	}
+	switch f.Type {
+	case "boolean":
+		if value == "true" {
+			return "Yes"
+		}
+		return "No"
+	case "select":
+		return opts.label(value)
+	case "select_multiple":
+		return strings.Join(opts.labels(splitList(value)), ", ")
+	case "attachment":
+		return attachmentName(value)
+	case "signature":
+		return "Signed"
+	}
+	return value
+}
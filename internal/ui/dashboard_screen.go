@@ -28,6 +28,8 @@ func DashboardScreen(
 	openForm func(name string),
 ) fyne.CanvasObject {
 
+	locale := forms.CurrentLocale(a)
+
 	// --- Build form cards ---
 	var cards []fyne.CanvasObject
 	for _, code := range order {
@@ -65,8 +67,8 @@ func DashboardScreen(
 		icon.SetMinSize(fyne.NewSize(40, 40))
 		icon.FillMode = canvas.ImageFillContain
 
-		title := widget.NewLabelWithStyle(meta.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
-		desc := widget.NewLabelWithStyle(meta.Description, fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
+		title := widget.NewLabelWithStyle(meta.Name.In(def.Locale(locale)), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		desc := widget.NewLabelWithStyle(meta.Description.In(def.Locale(locale)), fyne.TextAlignLeading, fyne.TextStyle{Italic: true})
 		desc.Wrapping = fyne.TextWrapWord
 
 		textBox := container.NewVBox(title, desc)
@@ -91,7 +93,7 @@ func DashboardScreen(
 	if len(drafts) > 0 {
 		count := len(drafts)
 		draftBtn = widget.NewButton(fmt.Sprintf("📂 View %d Draft%s", count, plural(count)), func() {
-			screen := DraftsScreen(a, apiURL, a.Driver().AllWindows()[0], func() {
+			screen := DraftsScreen(a, apiURL, formDefs, a.Driver().AllWindows()[0], func() {
 				main := DashboardScreen(a, formDefs, order, banner, openForm)
 				a.Driver().AllWindows()[0].SetContent(main)
 			})
@@ -137,7 +139,7 @@ func DashboardScreen(
 			fyne.Do(func() {
 				dialog.ShowInformation("Manual Sync", "Starting draft sync...", a.Driver().AllWindows()[0])
 			})
-			success, failed := forms.ManualSync(a, apiURL)
+			success, failed := forms.ManualSync(a, apiURL, formDefs)
 			msg := fmt.Sprintf("✅ %d uploaded, ❌ %d failed", success, failed)
 			fyne.Do(func() {
 				dialog.ShowInformation("Sync Complete", msg, a.Driver().AllWindows()[0])
@@ -215,16 +217,57 @@ func DashboardScreen(
 		isDrawerOpen = !isDrawerOpen
 	})
 
-	appBarContent := container.NewHBox(menuBtn, layout.NewSpacer(), titleLabel, layout.NewSpacer(), themeBtn, syncNowBtn)
+	var root *fyne.Container
+	barItems := []fyne.CanvasObject{menuBtn, layout.NewSpacer(), titleLabel, layout.NewSpacer()}
+
+	// --- Language switcher, only for forms with translations ---
+	if locales := forms.Locales(formDefs); len(locales) > 1 {
+		barItems = append(barItems, languageSelect(a, locales, func() {
+			// rebuild in place so the navigator's reference to this screen stays valid
+			fresh := DashboardScreen(a, formDefs, order, banner, openForm).(*fyne.Container)
+			root.Objects = fresh.Objects
+			root.Refresh()
+		}))
+	}
+
+	appBarContent := container.NewHBox(append(barItems, themeBtn, syncNowBtn)...)
 	appBar := container.NewMax(appBarBg, container.NewPadded(appBarContent))
 
 	content := container.NewVBox(appBar, banner, draftBtn, container.NewPadded(scroll))
 
 	// ✅ Drawer is purely an overlay, not part of navigation
-	root := container.NewStack(content, overlay, sideDrawer)
+	root = container.NewStack(content, overlay, sideDrawer)
 	return root
 }
 
+// languageSelect lets the user pick the locale forms are shown in.
+func languageSelect(a fyne.App, locales []string, onChange func()) *widget.Select {
+	names := make([]string, len(locales))
+	for i, l := range locales {
+		names[i] = forms.LocaleName(l)
+	}
+	sel := widget.NewSelect(names, nil)
+	current := forms.CurrentLocale(a)
+	for i, l := range locales {
+		if l == current {
+			sel.SetSelected(names[i])
+		}
+	}
+	if sel.Selected == "" {
+		// the forms' default locale comes first
+		sel.SetSelected(names[0])
+	}
+	sel.OnChanged = func(name string) {
+		for i, l := range locales {
+			if names[i] == name && l != forms.CurrentLocale(a) {
+				forms.SetLocale(a, l)
+				onChange()
+			}
+		}
+	}
+	return sel
+}
+
 func showDrawer(sideDrawer fyne.CanvasObject, overlay fyne.CanvasObject, width float32) {
 	sideDrawer.Show()
 	overlay.Show()
@@ -279,7 +322,8 @@ func buildSideDrawer(a fyne.App, onLogout func(), closeDrawer func()) fyne.Canva
 		onLogout()
 	})
 	settingsBtn := widget.NewButtonWithIcon("Settings", theme.SettingsIcon(), func() {
-		dialog.ShowInformation("Settings", "Settings coming soon.", a.Driver().AllWindows()[0])
+		closeDrawer()
+		showSettings(a)
 	})
 	aboutBtn := widget.NewButtonWithIcon("About", theme.InfoIcon(), func() {
 		dialog.ShowInformation("About", "Surveillance Forms v1.0", a.Driver().AllWindows()[0])
@@ -305,6 +349,32 @@ func buildSideDrawer(a fyne.App, onLogout func(), closeDrawer func()) fyne.Canva
 	return side
 }
 
+// showSettings shows the settings dialog.
+func showSettings(a fyne.App) {
+	win := a.Driver().AllWindows()[0]
+	clearBtn := widget.NewButtonWithIcon("Clear Remembered Values", theme.DeleteIcon(), func() {
+		dialog.ShowConfirm("Clear Remembered Values",
+			"Forget the facility, district and other values remembered from your last submission?",
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				forms.ClearStickyValues(a)
+				dialog.ShowInformation("Settings", "Remembered values cleared.", win)
+			}, win)
+	})
+	hint := widget.NewLabel("Some fields are prefilled with what you last submitted.")
+	hint.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(
+		widget.NewLabelWithStyle("Remembered Values", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		hint,
+		clearBtn,
+	)
+	d := dialog.NewCustom("Settings", "Close", content, win)
+	d.Resize(fyne.NewSize(320, 0))
+	d.Show()
+}
+
 // plural adds "s" for plural count
 func plural(n int) string {
 	if n == 1 {
@@ -21,7 +21,7 @@ import (
 
 // DraftsScreen displays saved drafts, allowing users to retry, preview, or delete them.
 // The list automatically refreshes after retry or delete.
-func DraftsScreen(a fyne.App, apiURL string, w fyne.Window, back func()) fyne.CanvasObject {
+func DraftsScreen(a fyne.App, apiURL string, formDefs map[string]forms.FormDefinition, w fyne.Window, back func()) fyne.CanvasObject {
 	listContainer := container.NewVBox()
 	var refreshList func()
 
@@ -69,7 +69,7 @@ func DraftsScreen(a fyne.App, apiURL string, w fyne.Window, back func()) fyne.Ca
 					status.SetText(fmt.Sprintf("Uploading %d of %d: %s", i+1, len(drafts), filepath.Base(d)))
 					canvas.Refresh(status)
 
-					err := forms.RetryDraft(a, apiURL, d)
+					err := forms.RetryDraft(a, apiURL, d, formDefs)
 					if err != nil {
 						failed++
 						fmt.Printf("❌ %s → %v\n", d, err)
@@ -158,7 +158,7 @@ func DraftsScreen(a fyne.App, apiURL string, w fyne.Window, back func()) fyne.Ca
 				return func() {
 					apiURL := "https://example.com/api/forms/submit"
 					go func() {
-						err := forms.RetryDraft(a, apiURL, path)
+						err := forms.RetryDraft(a, apiURL, path, formDefs)
 						fyne.Do(func() {
 							if err != nil {
 								dialog.ShowError(fmt.Errorf("Retry failed: %v", err), w)
@@ -0,0 +1,180 @@
+// Package signature provides a drawable pad for capturing handwritten signatures.
+package signature
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const strokeWidth = 2
+
+// Pad records pen strokes drawn with the mouse or a finger.
+// Undo removes the last stroke and Redo puts it back until a new stroke is drawn.
+type Pad struct {
+	widget.BaseWidget
+	OnChanged func() // called after every finished stroke, undo, redo or clear
+
+	strokes [][]fyne.Position
+	undone  [][]fyne.Position
+	drawing bool
+}
+
+func NewPad(onChanged func()) *Pad {
+	p := &Pad{OnChanged: onChanged}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+// IsEmpty reports whether nothing has been drawn.
+func (p *Pad) IsEmpty() bool {
+	return len(p.strokes) == 0
+}
+
+func (p *Pad) Clear() {
+	p.strokes = nil
+	p.undone = nil
+	p.Refresh()
+	p.changed()
+}
+
+func (p *Pad) Undo() {
+	if len(p.strokes) == 0 {
+		return
+	}
+	last := p.strokes[len(p.strokes)-1]
+	p.strokes = p.strokes[:len(p.strokes)-1]
+	p.undone = append(p.undone, last)
+	p.Refresh()
+	p.changed()
+}
+
+func (p *Pad) Redo() {
+	if len(p.undone) == 0 {
+		return
+	}
+	last := p.undone[len(p.undone)-1]
+	p.undone = p.undone[:len(p.undone)-1]
+	p.strokes = append(p.strokes, last)
+	p.Refresh()
+	p.changed()
+}
+
+func (p *Pad) changed() {
+	if p.OnChanged != nil {
+		p.OnChanged()
+	}
+}
+
+// --- Drawing ---
+func (p *Pad) Dragged(ev *fyne.DragEvent) {
+	if !p.drawing {
+		p.drawing = true
+		p.undone = nil
+		p.strokes = append(p.strokes, []fyne.Position{ev.Position.Subtract(ev.Dragged)})
+	}
+	last := len(p.strokes) - 1
+	p.strokes[last] = append(p.strokes[last], ev.Position)
+	p.Refresh()
+}
+
+func (p *Pad) DragEnd() {
+	p.drawing = false
+	p.changed()
+}
+
+// PNG renders the strokes in black on a white background at the pad's
+// current size.
+func (p *Pad) PNG() ([]byte, error) {
+	size := p.Size()
+	w, h := int(math.Ceil(float64(size.Width))), int(math.Ceil(float64(size.Height)))
+	if w <= 0 || h <= 0 {
+		w, h = 400, 150
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for _, stroke := range p.strokes {
+		for i := 1; i < len(stroke); i++ {
+			drawLine(img, stroke[i-1], stroke[i], color.Black)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine stamps small squares along the segment, giving a line of strokeWidth.
+func drawLine(img *image.RGBA, from, to fyne.Position, c color.Color) {
+	dx, dy := float64(to.X-from.X), float64(to.Y-from.Y)
+	steps := int(math.Max(math.Abs(dx), math.Abs(dy))) + 1
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		x := int(float64(from.X) + dx*t)
+		y := int(float64(from.Y) + dy*t)
+		for ox := 0; ox < strokeWidth; ox++ {
+			for oy := 0; oy < strokeWidth; oy++ {
+				img.Set(x+ox, y+oy, c)
+			}
+		}
+	}
+}
+
+// --- Renderer ---
+func (p *Pad) CreateRenderer() fyne.WidgetRenderer {
+	bg := canvas.NewRectangle(color.White)
+	bg.StrokeColor = theme.Color(theme.ColorNameInputBorder)
+	bg.StrokeWidth = 1
+	r := &padRenderer{pad: p, bg: bg}
+	r.Refresh()
+	return r
+}
+
+func (p *Pad) MinSize() fyne.Size {
+	return fyne.NewSize(300, 150)
+}
+
+type padRenderer struct {
+	pad   *Pad
+	bg    *canvas.Rectangle
+	lines []fyne.CanvasObject
+}
+
+func (r *padRenderer) Layout(size fyne.Size) {
+	r.bg.Resize(size)
+}
+
+func (r *padRenderer) MinSize() fyne.Size {
+	return r.pad.MinSize()
+}
+
+func (r *padRenderer) Refresh() {
+	r.lines = r.lines[:0]
+	for _, stroke := range r.pad.strokes {
+		for i := 1; i < len(stroke); i++ {
+			l := canvas.NewLine(color.Black)
+			l.StrokeWidth = strokeWidth
+			l.Position1 = stroke[i-1]
+			l.Position2 = stroke[i]
+			r.lines = append(r.lines, l)
+		}
+	}
+	r.bg.Refresh()
+	canvas.Refresh(r.pad)
+}
+
+func (r *padRenderer) Objects() []fyne.CanvasObject {
+	return append([]fyne.CanvasObject{r.bg}, r.lines...)
+}
+
+func (r *padRenderer) Destroy() {}
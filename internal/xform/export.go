@@ -0,0 +1,507 @@
+package xform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"forms-app/internal/forms"
+	"forms-app/internal/xpath"
+)
+
+// Form is a form definition together with the ID and version ODK Central
+// knows it by.
+type Form struct {
+	ID         string
+	Version    string
+	Definition forms.FormDefinition
+}
+
+// Export writes a form as an ODK XForm. Expressions that have no XPath
+// equivalent are left out and reported. Translated texts go into itext,
+// one translation per locale.
+func Export(form Form) ([]byte, []Problem, error) {
+	id, version, def := form.ID, form.Version, form.Definition
+	if id == "" {
+		return nil, nil, fmt.Errorf("a form ID is required")
+	}
+	e := &exporter{
+		locale:       def.Locale(""),
+		paths:        make(map[string]string),
+		instances:    make(map[string]bool),
+		translations: make(map[string]*node),
+		root:         el(rootName, "id", id, "version", version),
+		body:         el("h:body", "class", bodyClass(def.Layout)),
+	}
+	e.model = el("model",
+		"fa:name", e.textAttr("/"+rootName+":name", def.Meta.Name, true),
+		"fa:description", e.textAttr("/"+rootName+":description", def.Meta.Description, false),
+		"fa:icon", def.Meta.Icon,
+		"fa:defaultLocale", def.DefaultLocale)
+	// paths first: expressions may refer to fields further down the form
+	for _, sec := range def.Sections {
+		for _, f := range sec.Fields {
+			e.paths[f.ID] = "/" + rootName + "/" + sec.Key() + "/" + f.ID
+		}
+	}
+
+	primary := el("instance").add(e.root)
+	e.model.add(primary)
+	for _, sec := range def.Sections {
+		e.section(sec)
+	}
+	e.root.add(el("meta").add(el("instanceID")))
+	e.binds = append(e.binds, el("bind", "nodeset", "/"+rootName+"/meta/instanceID",
+		"type", "string", "readonly", "true()", "jr:preload", "uid"))
+	e.model.add(e.secondary...)
+	e.model.add(e.binds...)
+	e.model.add(e.setvalues...)
+	if len(e.translations) > 0 {
+		// itext comes first, as ODK tools write it
+		e.model.children = append([]*node{e.itext()}, e.model.children...)
+	}
+
+	title := def.Meta.Name.In(e.locale)
+	if title == "" {
+		title = id
+	}
+	html := el("h:html",
+		"xmlns", nsXForms, "xmlns:h", nsHTML, "xmlns:jr", nsJR, "xmlns:odk", nsODK, "xmlns:fa", nsApp)
+	html.add(el("h:head").add(el("h:title").withText(title), e.model), e.body)
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	html.write(&buf, 0)
+	return buf.Bytes(), e.problems, nil
+}
+
+type exporter struct {
+	locale       forms.Locale      // the form's default, for texts written once
+	paths        map[string]string // field ID -> instance path
+	instances    map[string]bool   // secondary instances already written
+	translations map[string]*node  // itext translation by locale
+	root         *node
+	model        *node
+	body         *node
+	secondary    []*node
+	binds        []*node
+	setvalues    []*node
+	problems     []Problem
+}
+
+func (e *exporter) report(ref, format string, args ...any) {
+	e.problems = append(e.problems, Problem{Ref: ref, Message: fmt.Sprintf(format, args...)})
+}
+
+// xpath translates an expression, reporting it if it has no equivalent.
+func (e *exporter) xpath(ref, what, expr string) string {
+	if expr == "" {
+		return ""
+	}
+	translated, err := xpath.FromExpr(expr, func(id string) (string, bool) {
+		path, ok := e.paths[id]
+		return path, ok
+	})
+	if err != nil {
+		e.report(ref, "%s %q left out: %v", what, expr, err)
+		return ""
+	}
+	return translated
+}
+
+// text renders a label or hint element, referring to itext if the text is
+// translated.
+func (e *exporter) text(tag, id string, t forms.Text) *node {
+	if !t.Translated() {
+		return el(tag).withText(t.String())
+	}
+	return el(tag, "ref", e.itextRef(id, t))
+}
+
+// textAttr returns the value of an attribute holding a text: the text
+// itself, or an itext reference if it is translated. Untranslated texts are
+// left out if the document already holds them elsewhere.
+func (e *exporter) textAttr(id string, t forms.Text, onlyTranslated bool) string {
+	if !t.Translated() {
+		if onlyTranslated {
+			return ""
+		}
+		return t.String()
+	}
+	return e.itextRef(id, t)
+}
+
+// itextRef adds a translated text to itext and returns its reference.
+func (e *exporter) itextRef(id string, t forms.Text) string {
+	for locale, s := range t {
+		tr, ok := e.translations[locale]
+		if !ok {
+			tr = el("translation", "lang", langName(locale))
+			if locale == e.locale.Default {
+				tr.set("default", "true()")
+			}
+			e.translations[locale] = tr
+		}
+		tr.add(el("text", "id", id).add(el("value").withText(s)))
+	}
+	return "jr:itext('" + id + "')"
+}
+
+// itext gathers the translations, in locale order.
+func (e *exporter) itext() *node {
+	locales := make([]string, 0, len(e.translations))
+	for locale := range e.translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	itext := el("itext")
+	for _, locale := range locales {
+		tr := e.translations[locale]
+		// texts were added locale by locale from maps; order them by ID
+		sort.SliceStable(tr.children, func(i, j int) bool {
+			return tr.children[i].attr("", "id") < tr.children[j].attr("", "id")
+		})
+		itext.add(tr)
+	}
+	return itext
+}
+
+// bodyClass is the body style matching a form layout: ODK's "pages" shows
+// one group per screen, like the wizard.
+func bodyClass(layout string) string {
+	if layout == "wizard" {
+		return "pages"
+	}
+	return ""
+}
+
+// langName names a locale the way XLSForm and pyxform do, e.g.
+// "Luganda (lg)"; untranslated text is the "default" language.
+func langName(locale string) string {
+	if locale == "" {
+		return "default"
+	}
+	return forms.LocaleName(locale) + " (" + locale + ")"
+}
+
+func (e *exporter) section(sec forms.Section) {
+	key := sec.Key()
+	path := "/" + rootName + "/" + key
+
+	data := el(key)
+	e.root.add(data)
+	group := el("group", "ref", path, "fa:layout", sec.Layout, "fa:columns", itoa(sec.Columns))
+	group.add(e.text("label", path+":label", sec.Title))
+	e.body.add(group)
+	if rel := e.xpath(key, "relevant", sec.Relevant); rel != "" {
+		e.binds = append(e.binds, el("bind", "nodeset", path, "relevant", rel))
+	}
+
+	controls := group
+	if sec.Repeat {
+		data.set("jr:template", "")
+		repeat := el("repeat", "nodeset", path,
+			"fa:minRepeat", itoa(sec.MinRepeat), "fa:maxRepeat", itoa(sec.MaxRepeat))
+		if sec.MinRepeat > 0 && sec.MinRepeat == sec.MaxRepeat {
+			repeat.set("jr:count", itoa(sec.MinRepeat))
+		}
+		group.add(repeat)
+		controls = repeat
+	}
+	if sec.ID != "" {
+		group.set("fa:id", sec.ID)
+	}
+
+	for _, f := range sec.Fields {
+		fieldPath := path + "/" + f.ID
+		value := el(f.ID)
+		data.add(value)
+		e.binds = append(e.binds, e.bind(f, fieldPath))
+
+		if f.Default != "" {
+			if strings.Contains(f.Default, "(") {
+				if expr := e.xpath(f.ID, "default", f.Default); expr != "" {
+					e.setvalues = append(e.setvalues, el("setvalue",
+						"event", "odk-instance-first-load", "ref", fieldPath, "value", expr))
+				}
+			} else {
+				value.withText(f.Default)
+			}
+		}
+		controls.add(e.control(f, fieldPath))
+	}
+}
+
+// bind describes a field's type and rules. Settings XForms can't express
+// go into forms-app attributes.
+func (e *exporter) bind(f forms.Field, path string) *node {
+	v := f.Validation
+	typ, ok := bindTypes[f.Type]
+	if !ok {
+		typ = "string"
+		if f.Calculate == "" {
+			e.report(f.ID, "type %q exported as text", f.Type)
+		}
+	}
+	b := el("bind", "nodeset", path, "type", typ, "fa:type", f.Type)
+	required := v.Required
+	for _, r := range v.Rules {
+		required = required || (r.Required && !r.Warning)
+	}
+	if required {
+		b.set("required", "true()")
+	}
+	b.set("relevant", e.xpath(f.ID, "relevant", f.Relevant))
+	if f.Calculate != "" {
+		b.set("calculate", e.xpath(f.ID, "calculate", f.Calculate))
+		b.set("readonly", "true()")
+	}
+	constraint, direct := e.constraint(f)
+	b.set("constraint", constraint)
+	b.set("jr:constraintMsg", e.textAttr(path+":jr:constraintMsg", v.ErrorMessage, false))
+
+	b.set("fa:sticky", boolAttr(f.Sticky))
+	b.set("fa:periodType", f.PeriodType)
+	b.set("fa:choiceList", f.ChoiceList)
+	b.set("fa:minDate", v.MinDate)
+	b.set("fa:maxDate", v.MaxDate)
+	b.set("fa:allowNegative", boolAttr(v.AllowNegative))
+	b.set("fa:decimalPlaces", itoa(v.DecimalPlaces))
+	b.set("fa:allowFuture", boolAttr(v.AllowFuture))
+	b.set("fa:maxSize", itoa(int(v.MaxSize)))
+	b.set("fa:allowedTypes", strings.Join(v.AllowedTypes, ","))
+	b.set("fa:warnings", strings.Join(v.Warnings, ","))
+	if len(v.Warnings) > 0 {
+		e.report(f.ID, "warning rules %s are hard constraints in XForms", strings.Join(v.Warnings, ", "))
+	}
+	// XForms has one constraint and message per field, so rules also go into
+	// fa:rules, with their messages and levels; fa:required and fa:constraint
+	// then hold what is set directly on the validation
+	if len(v.Rules) > 0 {
+		rules, err := json.Marshal(v.Rules)
+		if err != nil {
+			e.report(f.ID, "rules left out: %v", err)
+		} else {
+			b.set("fa:rules", string(rules))
+			b.set("fa:required", boolAttr(v.Required))
+			b.set("fa:constraint", direct)
+		}
+		for _, r := range v.Rules {
+			if r.Warning {
+				e.report(f.ID, "warning rules are only checked by forms-app")
+				break
+			}
+		}
+	}
+	return b
+}
+
+// constraint joins the field's validation rules into one XPath constraint,
+// leaving out rules that only warn; see parseConstraint for the reverse. It
+// also returns the part set directly on Validation, without Rules.
+func (e *exporter) constraint(f forms.Field) (all, direct string) {
+	v := f.Validation
+	parts := e.ruleParts(f, forms.Rule{
+		MinLength: v.MinLength, MaxLength: v.MaxLength,
+		Min: v.Min, Max: v.Max, Pattern: v.Pattern,
+		MinDate: v.MinDate, MaxDate: v.MaxDate,
+		GreaterThanField: v.GreaterThanField, LessThanField: v.LessThanField,
+		BeforeField: v.BeforeField, AfterField: v.AfterField,
+		EqualToField: v.EqualToField, NotEqualToField: v.NotEqualToField,
+		MinSelected: v.MinSelected, MaxSelected: v.MaxSelected,
+		Formula: v.Formula,
+	})
+	direct = strings.Join(parts, " and ")
+	for _, r := range v.Rules {
+		if !r.Warning {
+			parts = append(parts, e.ruleParts(f, r)...)
+		}
+	}
+	return strings.Join(parts, " and "), direct
+}
+
+// ruleParts expresses the checks of one rule as XPath conditions. Required,
+// maxSize and allowedTypes live in other attributes.
+func (e *exporter) ruleParts(f forms.Field, r forms.Rule) []string {
+	var parts []string
+	if r.Pattern != "" {
+		quoted := "'" + r.Pattern + "'"
+		if strings.Contains(r.Pattern, "'") {
+			quoted = `"` + r.Pattern + `"`
+		}
+		parts = append(parts, "regex(., "+quoted+")")
+	}
+	if r.MinLength > 0 {
+		parts = append(parts, "string-length(.) >= "+itoa(r.MinLength))
+	}
+	if r.MaxLength > 0 {
+		parts = append(parts, "string-length(.) <= "+itoa(r.MaxLength))
+	}
+	if numeric[f.Type] && r.Min != nil {
+		parts = append(parts, ". >= "+ftoa(*r.Min))
+	}
+	if numeric[f.Type] && r.Max != nil {
+		parts = append(parts, ". <= "+ftoa(*r.Max))
+	}
+	if r.MinSelected > 0 {
+		parts = append(parts, "count-selected(.) >= "+itoa(r.MinSelected))
+	}
+	if r.MaxSelected > 0 {
+		parts = append(parts, "count-selected(.) <= "+itoa(r.MaxSelected))
+	}
+	// relative bounds other than today and now only survive as fa:minDate/fa:maxDate
+	if bound, ok := boundXPath(f.Type, r.MinDate); ok {
+		parts = append(parts, ". >= "+bound)
+	}
+	if bound, ok := boundXPath(f.Type, r.MaxDate); ok {
+		parts = append(parts, ". <= "+bound)
+	}
+	for _, ref := range []struct{ op, id string }{
+		{">", r.GreaterThanField}, {"<", r.LessThanField},
+		{"<", r.BeforeField}, {">", r.AfterField},
+		{"=", r.EqualToField}, {"!=", r.NotEqualToField},
+	} {
+		if ref.id == "" {
+			continue
+		}
+		if path, ok := e.paths[ref.id]; ok {
+			parts = append(parts, ". "+ref.op+" "+path)
+		} else {
+			e.report(f.ID, "comparison with unknown field %q left out", ref.id)
+		}
+	}
+	if formula := e.xpath(f.ID, "formula", r.Formula); formula != "" {
+		parts = append(parts, "("+formula+")")
+	}
+	return parts
+}
+
+// boundXPath expresses a date bound in XPath, where that's possible.
+func boundXPath(fieldType, bound string) (string, bool) {
+	switch {
+	case bound == "":
+		return "", false
+	case bound == "today" && fieldType == "date":
+		return "today()", true
+	case bound == "now" && fieldType == "datetime":
+		return "now()", true
+	case fieldType == "date" && isDate(bound):
+		return "date('" + bound + "')", true
+	}
+	return "", false
+}
+
+// control renders the body element a field is filled in with.
+func (e *exporter) control(f forms.Field, path string) *node {
+	var c *node
+	switch f.Type {
+	case "select", "select_multiple":
+		c = el(map[string]string{"select": "select1", "select_multiple": "select"}[f.Type], "ref", path)
+	case "boolean":
+		c = el("trigger", "ref", path)
+	case "attachment":
+		mediatype := "application/*"
+		if types := f.Validation.AllowedTypes; len(types) == 1 && strings.HasSuffix(types[0], "/*") {
+			mediatype = types[0]
+		}
+		c = el("upload", "ref", path, "mediatype", mediatype)
+	case "signature":
+		c = el("upload", "ref", path, "mediatype", "image/*", "appearance", "signature")
+	case "multiline":
+		c = el("input", "ref", path, "appearance", "multiline")
+	default:
+		c = el("input", "ref", path)
+	}
+	c.set("fa:placeholder", e.textAttr(path+":placeholder", f.Placeholder, false))
+	c.add(e.text("label", path+":label", f.Label))
+	if f.Hint != nil {
+		c.add(e.text("hint", path+":hint", f.Hint))
+	}
+
+	switch {
+	case len(f.Choices) > 0:
+		c.add(e.itemset(f))
+	case len(f.Options) > 0:
+		for _, opt := range f.Options {
+			label := opt.Label
+			if label == nil {
+				label = forms.Plain(opt.Value)
+			}
+			c.add(el("item").add(e.text("label", path+"/"+opt.Value+":label", label), el("value").withText(opt.Value)))
+		}
+	}
+	return c
+}
+
+// itemset writes a field's choices as a secondary instance, with a column
+// per parent field, and refers to it from the control, filtered by
+// ChoiceFilter if the field has one.
+func (e *exporter) itemset(f forms.Field) *node {
+	list := f.ChoiceList
+	if list == "" {
+		list = f.ID
+	}
+	if !e.instances[list] {
+		e.instances[list] = true
+		root := el("root")
+		translated := false
+		for _, choice := range f.Choices {
+			label := choice.Value
+			if choice.Label != nil {
+				label = choice.Label.In(e.locale)
+				translated = translated || choice.Label.Translated()
+			}
+			item := el("item").add(el("name").withText(choice.Value), el("label").withText(label))
+			parents := make([]string, 0, len(choice.Parents))
+			for parent := range choice.Parents {
+				parents = append(parents, parent)
+			}
+			sort.Strings(parents)
+			for _, parent := range parents {
+				item.add(el(parent).withText(choice.Parents[parent]))
+			}
+			root.add(item)
+		}
+		if translated {
+			e.report(f.ID, "choice label translations of %q are left out", list)
+		}
+		e.secondary = append(e.secondary, el("instance", "id", list).add(root))
+	}
+
+	nodeset := "instance('" + list + "')/root/item"
+	if f.ChoiceFilter != "" {
+		if path, ok := e.paths[f.ChoiceFilter]; ok {
+			nodeset += "[" + f.ChoiceFilter + " = " + path + "]"
+		} else {
+			e.report(f.ID, "choice filter on unknown field %q left out", f.ChoiceFilter)
+		}
+	}
+	return el("itemset", "nodeset", nodeset).add(el("value", "ref", "name"), el("label", "ref", "label"))
+}
+
+// itoa formats a count, leaving zero (unset) empty so the attribute is skipped.
+func itoa(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func boolAttr(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+func isDate(s string) bool {
+	_, err := parseDate(s)
+	return err == nil
+}
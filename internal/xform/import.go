@@ -0,0 +1,731 @@
+package xform
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"forms-app/internal/forms"
+	"forms-app/internal/xpath"
+)
+
+var (
+	itextRef    = regexp.MustCompile(`^jr:itext\(\s*'([^']*)'\s*\)$`)
+	itemsetRef  = regexp.MustCompile(`^instance\('([^']+)'\)/root/item(?:\[\s*([\w-]+)\s*=\s*(\S+)\s*\])?$`)
+	lengthCheck = regexp.MustCompile(`^string-length\(\s*\.\s*\)\s*(>=|<=)\s*(\d+)$`)
+	countCheck  = regexp.MustCompile(`^count-selected\(\s*\.\s*\)\s*(>=|<=)\s*(\d+)$`)
+	selfCompare = regexp.MustCompile(`^\.\s*(>=|<=|!=|=|>|<)\s*(.+)$`)
+	dateLiteral = regexp.MustCompile(`^date\(\s*'([^']*)'\s*\)$`)
+)
+
+// Import reads an ODK XForm. Groups become sections, flattened where they
+// are nested, and groups inside a repeat pass their relevance on to its
+// fields. Controls and expressions that can't be mapped are skipped and
+// reported.
+func Import(r io.Reader) (Form, []Problem, error) {
+	doc, err := parse(r)
+	if err != nil {
+		return Form{}, nil, fmt.Errorf("reading XForm: %w", err)
+	}
+	models := doc.find("model")
+	if len(models) == 0 {
+		return Form{}, nil, fmt.Errorf("XForm has no model")
+	}
+	model := models[0]
+
+	imp := &importer{
+		binds:     make(map[string]*node),
+		setvalues: make(map[string]string),
+		defaults:  make(map[string]string),
+		secondary: make(map[string]*node),
+		itext:     make(map[string]forms.Text),
+		controls:  make(map[string]bool),
+	}
+	var primary *node
+	for _, inst := range model.children {
+		if inst.name.Local != "instance" || len(inst.children) == 0 {
+			continue
+		}
+		if id := inst.attr("", "id"); id != "" {
+			imp.secondary[id] = inst.children[0]
+		} else if primary == nil {
+			primary = inst.children[0]
+		}
+	}
+	if primary == nil {
+		return Form{}, nil, fmt.Errorf("XForm has no primary instance")
+	}
+	imp.readDefaults(primary, "/"+primary.name.Local)
+	for _, b := range model.find("bind") {
+		imp.binds[b.attr("", "nodeset")] = b
+	}
+	for _, sv := range doc.find("setvalue") {
+		imp.setvalues[sv.attr("", "ref")] = sv.attr("", "value")
+	}
+	imp.readItext(model)
+
+	form := Form{ID: primary.attr("", "id"), Version: primary.attr("", "version")}
+	def := &form.Definition
+	if titles := doc.find("title"); len(titles) > 0 {
+		def.Meta.Name = forms.Plain(titles[0].text)
+	}
+	if name := model.attr(nsApp, "name"); name != "" {
+		def.Meta.Name = imp.lookup(name)
+	}
+	def.Meta.Description = imp.lookup(model.attr(nsApp, "description"))
+	def.Meta.Icon = model.attr(nsApp, "icon")
+	def.DefaultLocale = model.attr(nsApp, "defaultLocale")
+	imp.title = def.Meta.Name
+
+	body := doc.child("body")
+	if body == nil {
+		return Form{}, nil, fmt.Errorf("XForm has no body")
+	}
+	if slices.Contains(strings.Fields(body.attr("", "class")), "pages") {
+		def.Layout = "wizard"
+	}
+	imp.walk(body, "/"+primary.name.Local)
+	imp.addCalculations(model.find("bind"))
+	def.Sections = imp.sections
+	return form, imp.problems, nil
+}
+
+// group is an open body group or repeat.
+type group struct {
+	node     *node
+	ref      string
+	label    forms.Text
+	relevant string
+	repeat   bool
+}
+
+type importer struct {
+	binds     map[string]*node      // by nodeset
+	setvalues map[string]string     // default expressions, by ref
+	defaults  map[string]string     // instance values, by path
+	secondary map[string]*node      // secondary instance roots, by ID
+	itext     map[string]forms.Text // translated texts, by ID
+	title     forms.Text
+
+	sections []forms.Section
+	refs     []string // instance path of each section
+	stack    []group
+	current  *forms.Section  // nil until the next field needs a section
+	controls map[string]bool // paths that have a body control
+	problems []Problem
+}
+
+func (imp *importer) report(ref, format string, args ...any) {
+	imp.problems = append(imp.problems, Problem{Ref: ref, Message: fmt.Sprintf(format, args...)})
+}
+
+// readDefaults records the values of leaf nodes in the primary instance.
+func (imp *importer) readDefaults(n *node, path string) {
+	for _, c := range n.children {
+		p := path + "/" + c.name.Local
+		if len(c.children) == 0 && c.text != "" {
+			imp.defaults[p] = c.text
+		}
+		imp.readDefaults(c, p)
+	}
+}
+
+// readItext loads the translations, keyed by locale code: "lg" for the
+// language "Luganda (lg)", "" for the "default" language.
+func (imp *importer) readItext(model *node) {
+	itext := model.child("itext")
+	if itext == nil {
+		return
+	}
+	for _, tr := range itext.children {
+		locale := localeCode(tr.attr("", "lang"))
+		for _, text := range tr.children {
+			for _, v := range text.children {
+				// media forms (image, audio...) aren't imported
+				if v.attr("", "form") != "" {
+					continue
+				}
+				id := text.attr("", "id")
+				if imp.itext[id] == nil {
+					imp.itext[id] = forms.Text{}
+				}
+				imp.itext[id][locale] = v.text
+				break
+			}
+		}
+	}
+}
+
+// localeCode returns the locale of an itext language: the code in
+// parentheses, "" for "default", or else the lower-case name.
+func localeCode(lang string) string {
+	if open := strings.LastIndex(lang, "("); open >= 0 && strings.HasSuffix(lang, ")") {
+		return strings.TrimSpace(lang[open+1 : len(lang)-1])
+	}
+	if lang == "default" {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+// text returns the text of a label or hint, following itext references.
+func (imp *importer) text(n *node) forms.Text {
+	if n == nil {
+		return nil
+	}
+	if ref := n.attr("", "ref"); ref != "" {
+		return imp.lookup(ref)
+	}
+	return forms.Plain(n.text)
+}
+
+// lookup returns an attribute holding a text or an itext reference.
+func (imp *importer) lookup(value string) forms.Text {
+	if m := itextRef.FindStringSubmatch(value); m != nil {
+		return imp.itext[m[1]]
+	}
+	return forms.Plain(value)
+}
+
+// resolve makes a control or group reference absolute.
+func resolve(ref, parent string) string {
+	if ref == "" || strings.HasPrefix(ref, "/") {
+		return ref
+	}
+	return parent + "/" + ref
+}
+
+func lastStep(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func (imp *importer) walk(parent *node, parentRef string) {
+	for _, c := range parent.children {
+		switch c.name.Local {
+		case "label", "hint":
+		case "group":
+			ref := resolve(c.attr("", "ref"), parentRef)
+			// ODK wraps each repeat in a group for its label
+			if rep := c.child("repeat"); rep != nil && resolve(rep.attr("", "nodeset"), parentRef) == ref {
+				imp.begin(c, rep, ref)
+				imp.walk(rep, ref)
+			} else {
+				imp.begin(c, nil, ref)
+				imp.walk(c, ref)
+			}
+			imp.end()
+		case "repeat":
+			ref := resolve(c.attr("", "nodeset"), parentRef)
+			imp.begin(c, c, ref)
+			imp.walk(c, ref)
+			imp.end()
+		case "input", "select1", "select", "upload", "trigger":
+			ref := resolve(c.attr("", "ref"), parentRef)
+			imp.controls[ref] = true
+			if f, ok := imp.field(c, ref); ok {
+				imp.addField(f)
+			}
+		default:
+			imp.report(resolve(c.attr("", "ref"), parentRef), "%s controls are not supported; skipped", c.name.Local)
+		}
+	}
+}
+
+// begin opens a group or repeat. Top-level groups and repeats start a new
+// section; groups inside a repeat only add their relevance to its fields.
+func (imp *importer) begin(g, repeat *node, ref string) {
+	grp := group{node: g, ref: ref, label: imp.text(g.child("label"))}
+	if grp.label == nil {
+		grp.label = forms.Plain(lastStep(ref))
+	}
+	if b := imp.binds[ref]; b != nil {
+		grp.relevant = imp.expr(ref, "relevant", b.attr("", "relevant"), "")
+	}
+	if imp.inRepeat() {
+		if repeat != nil {
+			imp.report(ref, "repeat inside a repeat is imported as a group")
+		}
+		imp.stack = append(imp.stack, grp)
+		return
+	}
+	grp.repeat = repeat != nil
+	imp.stack = append(imp.stack, grp)
+	imp.current = nil
+
+	if repeat != nil {
+		sec := imp.section()
+		sec.MinRepeat = atoi(repeat.attr(nsApp, "minRepeat"))
+		sec.MaxRepeat = atoi(repeat.attr(nsApp, "maxRepeat"))
+		if count := strings.TrimSpace(repeat.attr(nsJR, "count")); count != "" && sec.MinRepeat == 0 && sec.MaxRepeat == 0 {
+			if n, err := strconv.Atoi(count); err == nil {
+				sec.MinRepeat, sec.MaxRepeat = n, n
+			} else {
+				imp.report(ref, "repeat count %q is not a number and is ignored", count)
+			}
+		}
+	}
+}
+
+func (imp *importer) end() {
+	imp.stack = imp.stack[:len(imp.stack)-1]
+	if !imp.inRepeat() {
+		imp.current = nil // fields after this go into a new section
+	}
+}
+
+func (imp *importer) inRepeat() bool {
+	for _, g := range imp.stack {
+		if g.repeat {
+			return true
+		}
+	}
+	return false
+}
+
+// section returns the section new fields go into, starting one for the open
+// groups if needed.
+func (imp *importer) section() *forms.Section {
+	if imp.current != nil {
+		return imp.current
+	}
+	sec := forms.Section{Title: imp.title, Layout: "stack"}
+	ref := ""
+	var titles []forms.Text
+	var relevant []string
+	for _, g := range imp.stack {
+		titles = append(titles, g.label)
+		if g.relevant != "" {
+			relevant = append(relevant, g.relevant)
+		}
+		sec.Repeat = sec.Repeat || g.repeat
+		if g.repeat || !sec.Repeat {
+			ref = g.ref
+		}
+	}
+	if len(titles) > 0 {
+		innermost := imp.stack[len(imp.stack)-1].node
+		sec.Title = forms.JoinText(titles, " / ")
+		if layout := innermost.attr(nsApp, "layout"); layout != "" {
+			sec.Layout = layout
+		}
+		sec.Columns = atoi(innermost.attr(nsApp, "columns"))
+		sec.ID = innermost.attr(nsApp, "id")
+		// keep the instance name as the payload key unless the title
+		// already produces it
+		if name := lastStep(ref); sec.ID == "" && sec.Key() != name {
+			sec.ID = name
+		}
+	}
+	sec.Relevant = joinAnd(relevant)
+	imp.sections = append(imp.sections, sec)
+	imp.refs = append(imp.refs, ref)
+	imp.current = &imp.sections[len(imp.sections)-1]
+	return imp.current
+}
+
+func (imp *importer) addField(f forms.Field) {
+	// groups inside a repeat pass their relevance on to each field
+	var relevant []string
+	inRepeat := false
+	for _, g := range imp.stack {
+		if inRepeat && g.relevant != "" {
+			relevant = append(relevant, g.relevant)
+		}
+		inRepeat = inRepeat || g.repeat
+	}
+	if f.Relevant != "" {
+		relevant = append(relevant, f.Relevant)
+	}
+	f.Relevant = joinAnd(relevant)
+
+	sec := imp.section()
+	sec.Fields = append(sec.Fields, f)
+}
+
+// addCalculations imports calculate binds that have no body control, which
+// is how ODK forms hold hidden calculations, into the section of their
+// parent group, or a section of their own.
+func (imp *importer) addCalculations(binds []*node) {
+	var rest []forms.Field
+	for _, b := range binds {
+		ref := b.attr("", "nodeset")
+		if b.attr("", "calculate") == "" || imp.controls[ref] {
+			continue
+		}
+		f, ok := imp.field(&node{name: xml.Name{Local: "input"}}, ref)
+		if !ok || f.Calculate == "" {
+			continue
+		}
+		f.Label = forms.Plain(f.ID)
+		parent := ref[:strings.LastIndex(ref, "/")]
+		placed := false
+		for i := range imp.sections {
+			if imp.refs[i] == parent {
+				imp.sections[i].Fields = append(imp.sections[i].Fields, f)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			rest = append(rest, f)
+		}
+	}
+	if len(rest) > 0 {
+		imp.sections = append(imp.sections, forms.Section{Title: imp.title, Layout: "stack", Fields: rest})
+	}
+}
+
+func joinAnd(exprs []string) string {
+	if len(exprs) < 2 {
+		return strings.Join(exprs, "")
+	}
+	return "(" + strings.Join(exprs, ") && (") + ")"
+}
+
+// expr converts an XPath expression, reporting it if it can't be.
+func (imp *importer) expr(ref, what, raw, self string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	converted, err := xpath.ToExpr(raw, self)
+	if err != nil {
+		imp.report(ref, "%s %q not imported: %v", what, raw, err)
+		return ""
+	}
+	return converted
+}
+
+// field maps a body control and its bind onto a field.
+func (imp *importer) field(c *node, ref string) (forms.Field, bool) {
+	id := lastStep(ref)
+	b := imp.binds[ref]
+	if b == nil {
+		b = &node{}
+	}
+	appearance := c.attr("", "appearance")
+
+	typ := b.attr(nsApp, "type")
+	if typ == "" {
+		switch c.name.Local {
+		case "select1":
+			typ = "select"
+		case "select":
+			typ = "select_multiple"
+		case "trigger":
+			typ = "boolean"
+		case "upload":
+			typ = "attachment"
+			if appearance == "signature" {
+				typ = "signature"
+			}
+		default:
+			typ = importTypes[b.attr("", "type")]
+			if typ == "text" && appearance == "multiline" {
+				typ = "multiline"
+			}
+		}
+		if typ == "" {
+			imp.report(ref, "type %q is not supported; skipped", b.attr("", "type"))
+			return forms.Field{}, false
+		}
+	}
+
+	f := forms.Field{
+		ID:          id,
+		Label:       imp.text(c.child("label")),
+		Type:        typ,
+		Hint:        imp.text(c.child("hint")),
+		Placeholder: imp.lookup(c.attr(nsApp, "placeholder")),
+		PeriodType:  b.attr(nsApp, "periodType"),
+		ChoiceList:  b.attr(nsApp, "choiceList"),
+		Sticky:      b.attr(nsApp, "sticky") == "true",
+	}
+	if c.name.Local == "upload" && typ == "attachment" && b.attr(nsApp, "type") == "" {
+		if mediatype := c.attr("", "mediatype"); mediatype != "" && mediatype != "application/*" {
+			f.Validation.AllowedTypes = []string{mediatype}
+		}
+	}
+
+	// forms-app rules come back from fa:rules; fa:required and fa:constraint
+	// then hold what was set directly on the validation
+	required, constraint := b.attr("", "required"), b.attr("", "constraint")
+	if rules := b.attr(nsApp, "rules"); rules != "" {
+		if err := json.Unmarshal([]byte(rules), &f.Validation.Rules); err != nil {
+			imp.report(ref, "fa:rules left out: %v", err)
+		} else {
+			required, constraint = "", b.attr(nsApp, "constraint")
+			if b.attr(nsApp, "required") == "true" {
+				required = "true()"
+			}
+		}
+	}
+	switch req := required; req {
+	case "", "false()":
+	case "true()":
+		f.Validation.Required = true
+	default:
+		imp.report(ref, "conditional required %q is not supported", req)
+	}
+	f.Relevant = imp.expr(ref, "relevant", b.attr("", "relevant"), id)
+	f.Calculate = imp.expr(ref, "calculate", b.attr("", "calculate"), id)
+	if b.attr("", "readonly") == "true()" && f.Calculate == "" {
+		imp.report(ref, "read-only fields are only imported with a calculation")
+	}
+	imp.constraint(&f, ref, constraint)
+	f.Validation.ErrorMessage = imp.lookup(b.attr(nsJR, "constraintMsg"))
+
+	v := &f.Validation
+	if d := b.attr(nsApp, "minDate"); d != "" {
+		v.MinDate = d
+	}
+	if d := b.attr(nsApp, "maxDate"); d != "" {
+		v.MaxDate = d
+	}
+	v.AllowNegative = b.attr(nsApp, "allowNegative") == "true"
+	v.DecimalPlaces = atoi(b.attr(nsApp, "decimalPlaces"))
+	v.AllowFuture = b.attr(nsApp, "allowFuture") == "true"
+	v.MaxSize = int64(atoi(b.attr(nsApp, "maxSize")))
+	if types := b.attr(nsApp, "allowedTypes"); types != "" {
+		v.AllowedTypes = strings.Split(types, ",")
+	}
+	if warnings := b.attr(nsApp, "warnings"); warnings != "" {
+		v.Warnings = strings.Split(warnings, ",")
+	}
+
+	if expr, ok := imp.setvalues[ref]; ok {
+		f.Default = imp.expr(ref, "default", expr, id)
+	} else {
+		f.Default = imp.defaults[ref]
+	}
+
+	imp.choices(&f, c, ref)
+	return f, true
+}
+
+// choices reads inline items into Options and an itemset into Choices.
+func (imp *importer) choices(f *forms.Field, c *node, ref string) {
+	for _, item := range c.children {
+		if item.name.Local != "item" {
+			continue
+		}
+		value := item.child("value")
+		if value == nil {
+			continue
+		}
+		// a label that only repeats the value adds nothing
+		opt := forms.Option{Value: value.text}
+		if label := imp.text(item.child("label")); label.Translated() || label.String() != value.text {
+			opt.Label = label
+		}
+		f.Options = append(f.Options, opt)
+	}
+
+	if set := c.child("itemset"); set != nil {
+		m := itemsetRef.FindStringSubmatch(set.attr("", "nodeset"))
+		if m == nil {
+			imp.report(ref, "itemset %q is not supported", set.attr("", "nodeset"))
+			return
+		}
+		list, column, parentRef := m[1], m[2], m[3]
+		root := imp.secondary[list]
+		if root == nil {
+			imp.report(ref, "itemset refers to missing instance %q", list)
+			return
+		}
+		valueRef := "name"
+		if v := set.child("value"); v != nil && v.attr("", "ref") != "" {
+			valueRef = v.attr("", "ref")
+		}
+		parent := ""
+		if column != "" {
+			parent = lastStep(strings.TrimSuffix(strings.TrimPrefix(parentRef, "${"), "}"))
+			f.ChoiceFilter = parent
+		}
+		for _, item := range root.children {
+			choice := forms.Choice{}
+			for _, cell := range item.children {
+				switch cell.name.Local {
+				case valueRef:
+					choice.Value = cell.text
+				case "label":
+					if cell.text != "" && cell.text != item.child(valueRef).text {
+						choice.Label = forms.Plain(cell.text)
+					}
+				default:
+					if choice.Parents == nil {
+						choice.Parents = make(map[string]string)
+					}
+					key := cell.name.Local
+					if key == column {
+						key = parent
+					}
+					choice.Parents[key] = cell.text
+				}
+			}
+			f.Choices = append(f.Choices, choice)
+		}
+	}
+}
+
+// constraint maps the parts of an XPath constraint back onto validation
+// rules; whatever isn't recognised becomes the formula.
+func (imp *importer) constraint(f *forms.Field, ref, constraint string) {
+	if constraint == "" {
+		return
+	}
+	v := &f.Validation
+	var formulas []string
+	for _, part := range splitAnd(constraint) {
+		if pattern, ok := xpath.RegexPattern(part); ok {
+			v.Pattern = pattern
+			continue
+		}
+		if m := lengthCheck.FindStringSubmatch(part); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			if m[1] == ">=" {
+				v.MinLength = n
+			} else {
+				v.MaxLength = n
+			}
+			continue
+		}
+		if m := countCheck.FindStringSubmatch(part); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			if m[1] == ">=" {
+				v.MinSelected = n
+			} else {
+				v.MaxSelected = n
+			}
+			continue
+		}
+		if m := selfCompare.FindStringSubmatch(part); m != nil && imp.comparison(f, m[1], strings.TrimSpace(m[2])) {
+			continue
+		}
+		if expr := imp.expr(ref, "constraint", unwrap(part), f.ID); expr != "" {
+			formulas = append(formulas, expr)
+		}
+	}
+	v.Formula = joinAnd(formulas)
+}
+
+// comparison maps ". op value" onto a range, date bound or cross-field rule.
+func (imp *importer) comparison(f *forms.Field, op, rhs string) bool {
+	v := &f.Validation
+	if num, err := strconv.ParseFloat(rhs, 64); err == nil && numeric[f.Type] {
+		switch op {
+		case ">=":
+			v.Min = &num
+			return true
+		case "<=":
+			v.Max = &num
+			return true
+		}
+		return false
+	}
+
+	bound := ""
+	switch {
+	case rhs == "today()":
+		bound = "today"
+	case rhs == "now()":
+		bound = "now"
+	case dateLiteral.MatchString(rhs):
+		bound = dateLiteral.FindStringSubmatch(rhs)[1]
+	}
+	if bound != "" {
+		switch op {
+		case ">=":
+			v.MinDate = bound
+			return true
+		case "<=":
+			v.MaxDate = bound
+			return true
+		}
+		return false
+	}
+
+	if !strings.HasPrefix(rhs, "/") && !strings.HasPrefix(rhs, "${") || strings.ContainsAny(rhs, " ()") {
+		return false
+	}
+	other := lastStep(strings.TrimSuffix(strings.TrimPrefix(rhs, "${"), "}"))
+	switch {
+	case op == ">" && temporal[f.Type]:
+		v.AfterField = other
+	case op == "<" && temporal[f.Type]:
+		v.BeforeField = other
+	case op == ">":
+		v.GreaterThanField = other
+	case op == "<":
+		v.LessThanField = other
+	case op == "=":
+		v.EqualToField = other
+	case op == "!=":
+		v.NotEqualToField = other
+	default:
+		return false
+	}
+	return true
+}
+
+// splitAnd splits an expression on its top-level "and"s.
+func splitAnd(expr string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		ch := expr[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(' || ch == '[':
+			depth++
+		case ch == ')' || ch == ']':
+			depth--
+		case depth == 0 && strings.HasPrefix(expr[i:], " and "):
+			parts = append(parts, strings.TrimSpace(expr[start:i]))
+			start = i + len(" and ")
+			i = start - 1
+		}
+	}
+	return append(parts, strings.TrimSpace(expr[start:]))
+}
+
+// unwrap strips parentheses around a whole expression.
+func unwrap(expr string) string {
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		depth := 0
+		for i := 0; i < len(expr); i++ {
+			switch expr[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 && i < len(expr)-1 {
+				return expr // the first parenthesis closes early
+			}
+		}
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func parseDate(s string) (time.Time, error) {
+	return time.Parse("2006-01-02", s)
+}
@@ -0,0 +1,73 @@
+// Package xform converts form definitions to and from ODK XForms, the XML
+// format read by ODK Collect and ODK Central.
+//
+// Sections become groups (repeat sections become repeats), fields become
+// binds and body controls, and required, relevant, calculate and validation
+// rules become bind attributes and constraints. Settings XForms has no place
+// for, such as relative date bounds or sticky fields, are kept as attributes
+// in the forms-app namespace, which ODK ignores, so that a form exported and
+// imported again comes back unchanged.
+package xform
+
+import "fmt"
+
+// Namespaces used in the generated XML, and matched by URL when reading.
+const (
+	nsXForms = "http://www.w3.org/2002/xforms"
+	nsHTML   = "http://www.w3.org/1999/xhtml"
+	nsJR     = "http://openrosa.org/javarosa"
+	nsODK    = "http://www.opendatakit.org/xforms"
+	nsApp    = "https://forms-app/xforms"
+)
+
+// rootName is the element name of the primary instance.
+const rootName = "data"
+
+// Problem is something that couldn't be converted as is.
+type Problem struct {
+	Ref     string // field, section or XForm node the problem is about
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Ref, p.Message)
+}
+
+// bindTypes maps field types onto XForm bind types.
+var bindTypes = map[string]string{
+	"text":            "string",
+	"multiline":       "string",
+	"period":          "string",
+	"number":          "decimal",
+	"decimal":         "decimal",
+	"integer":         "int",
+	"select":          "string",
+	"select_multiple": "string",
+	"boolean":         "string",
+	"date":            "date",
+	"time":            "time",
+	"datetime":        "dateTime",
+	"geopoint":        "geopoint",
+	"attachment":      "binary",
+	"signature":       "binary",
+}
+
+// importTypes maps XForm bind types onto field types, for forms that don't
+// carry the forms-app type attribute.
+var importTypes = map[string]string{
+	"string":   "text",
+	"int":      "integer",
+	"integer":  "integer",
+	"decimal":  "decimal",
+	"date":     "date",
+	"time":     "time",
+	"dateTime": "datetime",
+	"geopoint": "geopoint",
+	"binary":   "attachment",
+}
+
+// numeric are the field types Min and Max apply to.
+var numeric = map[string]bool{"number": true, "integer": true, "decimal": true}
+
+// temporal are the field types compared as points in time.
+var temporal = map[string]bool{"date": true, "time": true, "datetime": true, "period": true}
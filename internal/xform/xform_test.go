@@ -0,0 +1,47 @@
+package xform
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"forms-app/internal/forms"
+)
+
+// TestRoundTrip exports every bundled form and imports it again: what
+// comes back must be the form that went out.
+func TestRoundTrip(t *testing.T) {
+	defs, order, err := forms.LoadFromEmbedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range order {
+		t.Run(code, func(t *testing.T) {
+			want := Form{ID: code, Version: "1", Definition: defs[code]}
+			xml, _, err := Export(want)
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			got, _, err := Import(bytes.NewReader(xml))
+			if err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			if got.ID != want.ID || got.Version != want.Version {
+				t.Errorf("got form %q version %q, want %q version %q", got.ID, got.Version, want.ID, want.Version)
+			}
+			// compared as JSON, as forms.json holds them: nil and empty
+			// lists are the same there
+			g, err := json.MarshalIndent(got.Definition, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			w, err := json.MarshalIndent(want.Definition, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(g, w) {
+				t.Errorf("definition changed on the way:\ngot  %s\nwant %s", g, w)
+			}
+		})
+	}
+}
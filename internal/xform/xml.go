@@ -0,0 +1,151 @@
+package xform
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// node is a minimal XML element tree, enough to write and read XForms
+// without mirroring the whole XForms vocabulary in structs.
+type node struct {
+	name     xml.Name // Space holds the namespace URL when read, the prefix when written
+	attrs    []xml.Attr
+	text     string
+	children []*node
+}
+
+func el(name string, attrs ...string) *node {
+	n := &node{name: xml.Name{Local: name}}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		n.set(attrs[i], attrs[i+1])
+	}
+	return n
+}
+
+// set adds an attribute, skipping empty values; names may have a prefix.
+func (n *node) set(name, value string) *node {
+	if value != "" {
+		n.attrs = append(n.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+	return n
+}
+
+func (n *node) add(children ...*node) *node {
+	n.children = append(n.children, children...)
+	return n
+}
+
+func (n *node) withText(text string) *node {
+	n.text = text
+	return n
+}
+
+// attr returns the value of an attribute read from a document, by namespace
+// URL ("" for none) and local name.
+func (n *node) attr(space, local string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// child returns the first child element with the given local name.
+func (n *node) child(local string) *node {
+	for _, c := range n.children {
+		if c.name.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// find returns all descendants with the given local name, in document order.
+func (n *node) find(local string) []*node {
+	var found []*node
+	for _, c := range n.children {
+		if c.name.Local == local {
+			found = append(found, c)
+		}
+		found = append(found, c.find(local)...)
+	}
+	return found
+}
+
+// attrEscaper escapes attribute values, leaving the single quotes of
+// XPath strings readable.
+var attrEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;",
+	"\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
+
+// write renders the tree as indented XML.
+func (n *node) write(w *bytes.Buffer, depth int) {
+	indent := strings.Repeat("  ", depth)
+	w.WriteString(indent + "<" + n.name.Local)
+	for _, a := range n.attrs {
+		w.WriteString(" " + a.Name.Local + `="` + attrEscaper.Replace(a.Value) + `"`)
+	}
+	switch {
+	case len(n.children) > 0:
+		w.WriteString(">\n")
+		for _, c := range n.children {
+			c.write(w, depth+1)
+		}
+		w.WriteString(indent + "</" + n.name.Local + ">\n")
+	case n.text != "":
+		w.WriteString(">")
+		xml.EscapeText(w, []byte(n.text))
+		w.WriteString("</" + n.name.Local + ">\n")
+	default:
+		w.WriteString("/>\n")
+	}
+}
+
+// parse reads a document into a tree. Text is trimmed and mixed content
+// flattened, which is all XForms labels and values need.
+func parse(r io.Reader) (*node, error) {
+	dec := xml.NewDecoder(r)
+	var stack []*node
+	var root *node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+	trimText(root)
+	return root, nil
+}
+
+func trimText(n *node) {
+	n.text = strings.TrimSpace(n.text)
+	for _, c := range n.children {
+		trimText(c)
+	}
+}
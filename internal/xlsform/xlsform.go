@@ -0,0 +1,545 @@
+// Package xlsform imports forms written in XLSForm, the spreadsheet format
+// used by ODK, from the CSV export of its survey, choices and settings sheets.
+package xlsform
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"forms-app/internal/forms"
+	"forms-app/internal/xpath"
+)
+
+// Sheets holds the CSV exports of an XLSForm's sheets. Choices and Settings
+// are optional.
+type Sheets struct {
+	Survey   io.Reader
+	Choices  io.Reader
+	Settings io.Reader
+}
+
+// Problem is something in the XLSForm that couldn't be imported as is.
+type Problem struct {
+	Sheet   string
+	Row     int // line in the CSV file, 0 for the sheet as a whole
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Row == 0 {
+		return fmt.Sprintf("%s: %s", p.Sheet, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.Sheet, p.Row, p.Message)
+}
+
+// metadataTypes are filled in by ODK itself; they have no question to import.
+var metadataTypes = map[string]bool{
+	"start": true, "end": true, "today": true, "deviceid": true, "subscriberid": true,
+	"simserial": true, "phonenumber": true, "username": true, "email": true,
+	"audit": true, "start-geopoint": true, "background-audio": true,
+}
+
+// simpleTypes maps XLSForm question types onto field types one to one.
+var simpleTypes = map[string]string{
+	"text":        "text",
+	"integer":     "integer",
+	"decimal":     "decimal",
+	"date":        "date",
+	"time":        "time",
+	"datetime":    "datetime",
+	"geopoint":    "geopoint",
+	"acknowledge": "boolean",
+	"image":       "attachment",
+	"audio":       "attachment",
+	"video":       "attachment",
+	"file":        "attachment",
+	"calculate":   "text",
+}
+
+// mediaTypes limits media questions to the matching MIME types.
+var mediaTypes = map[string]string{
+	"image": "image/*",
+	"audio": "audio/*",
+	"video": "video/*",
+}
+
+// ImportDir imports survey.csv, and choices.csv and settings.csv if present,
+// from dir.
+func ImportDir(dir string) (forms.FormBundle, []Problem, error) {
+	var sheets Sheets
+	for name, r := range map[string]*io.Reader{"survey": &sheets.Survey, "choices": &sheets.Choices, "settings": &sheets.Settings} {
+		f, err := os.Open(filepath.Join(dir, name+".csv"))
+		if os.IsNotExist(err) && name != "survey" {
+			continue
+		}
+		if err != nil {
+			return forms.FormBundle{}, nil, err
+		}
+		defer f.Close()
+		*r = f
+	}
+	return Import(sheets)
+}
+
+// Import converts an XLSForm into a bundle holding a single form, keyed by
+// the form_id setting. Groups and repeats become sections; nested groups are
+// flattened into one section each, with their relevance combined. Choice
+// lists become bundle choice lists: choice names are the submitted values and
+// their labels, with translations, are what the form shows. Everything that
+// couldn't be mapped is listed in the returned problems.
+func Import(s Sheets) (forms.FormBundle, []Problem, error) {
+	if s.Survey == nil {
+		return forms.FormBundle{}, nil, fmt.Errorf("the survey sheet is required")
+	}
+	imp := &importer{bundle: forms.FormBundle{Choices: make(map[string][]forms.Choice)}}
+
+	settings := map[string]string{}
+	if s.Settings != nil {
+		rows, err := readSheet("settings", s.Settings)
+		if err != nil {
+			return forms.FormBundle{}, nil, err
+		}
+		if len(rows) > 0 {
+			settings = rows[0].cells
+		}
+	}
+	if s.Choices != nil {
+		rows, err := readSheet("choices", s.Choices)
+		if err != nil {
+			return forms.FormBundle{}, nil, err
+		}
+		imp.readChoices(rows)
+	}
+	rows, err := readSheet("survey", s.Survey)
+	if err != nil {
+		return forms.FormBundle{}, nil, err
+	}
+
+	formID := settings["form_id"]
+	if formID == "" {
+		formID = "FORM"
+		imp.report("settings", 0, "no form_id; the form is keyed %q", formID)
+	}
+	title := settings["form_title"]
+	if title == "" {
+		title = formID
+	}
+	imp.title = forms.Plain(title)
+
+	imp.readSurvey(rows)
+
+	def := forms.FormDefinition{
+		Meta:     forms.FormMeta{Name: imp.title},
+		Sections: imp.sections,
+	}
+	// ODK's "pages" style shows one group per screen, like our wizard
+	if slices.Contains(strings.Fields(settings["style"]), "pages") {
+		def.Layout = "wizard"
+	}
+	imp.bundle.Forms = map[string]forms.FormDefinition{formID: def}
+	imp.bundle.FormOrder = []string{formID}
+	imp.bundle.Version = settings["version"]
+	if len(imp.bundle.Choices) == 0 {
+		imp.bundle.Choices = nil
+	}
+	return imp.bundle, imp.problems, nil
+}
+
+// row is one line of a sheet, with cells keyed by lower-case column name.
+type row struct {
+	line  int
+	cells map[string]string
+}
+
+// get returns the cell of a column, falling back to the first translated
+// column ("label::English (en)") when the plain one is missing.
+func (r row) get(column string) string {
+	if v, ok := r.cells[column]; ok {
+		return v
+	}
+	return r.cells[column+"::"]
+}
+
+// text returns a column with its translations: "label::Luganda (lg)" is
+// the "lg" translation of "label". A plain column is the untranslated text.
+func (r row) text(column string) forms.Text {
+	t := forms.Text{}
+	for key, v := range r.cells {
+		if v == "" {
+			continue
+		}
+		if key == column {
+			t[""] = v
+		} else if lang, ok := strings.CutPrefix(key, column+"::"); ok && lang != "" {
+			t[localeCode(lang)] = v
+		}
+	}
+	if len(t) == 0 {
+		return nil
+	}
+	return t
+}
+
+// localeCode returns the code of an XLSForm language, "lg" for
+// "Luganda (lg)", or the lower-case name if it has none.
+func localeCode(lang string) string {
+	if open := strings.LastIndex(lang, "("); open >= 0 && strings.HasSuffix(lang, ")") {
+		return strings.TrimSpace(lang[open+1 : len(lang)-1])
+	}
+	return strings.ToLower(strings.TrimSpace(lang))
+}
+
+// readSheet reads a CSV sheet into rows, skipping blank lines. Translated
+// columns ("label::English (en)") are kept under their own name, and the
+// first translation of each column is also kept as "label::" for get.
+func readSheet(sheet string, r io.Reader) ([]row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s sheet: %w", sheet, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	}
+
+	var rows []row
+	for n, rec := range records[1:] {
+		cells := make(map[string]string)
+		blank := true
+		for i, v := range rec {
+			if i >= len(header) || header[i] == "" {
+				continue
+			}
+			v = strings.TrimSpace(v)
+			cells[header[i]] = v
+			if base, _, ok := strings.Cut(header[i], "::"); ok {
+				if _, seen := cells[base+"::"]; !seen {
+					cells[base+"::"] = v
+				}
+			}
+			if v != "" {
+				blank = false
+			}
+		}
+		if !blank {
+			rows = append(rows, row{line: n + 2, cells: cells})
+		}
+	}
+	return rows, nil
+}
+
+// group is an open group or repeat in the survey sheet.
+type group struct {
+	name, relevant string
+	label          forms.Text
+	repeat         bool
+}
+
+type importer struct {
+	bundle   forms.FormBundle
+	problems []Problem
+	title    forms.Text
+
+	sections []forms.Section
+	stack    []group
+	current  *forms.Section // nil until the next field needs a section
+	// choiceRows keeps the choices sheet rows of each list, whose extra
+	// columns hold the parents used by choice filters
+	choiceRows map[string][]row
+}
+
+func (imp *importer) report(sheet string, line int, format string, args ...any) {
+	imp.problems = append(imp.problems, Problem{Sheet: sheet, Row: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (imp *importer) readChoices(rows []row) {
+	imp.choiceRows = make(map[string][]row)
+	for _, r := range rows {
+		list, name := r.get("list_name"), r.get("name")
+		if list == "" || name == "" {
+			imp.report("choices", r.line, "choice without list_name or name skipped")
+			continue
+		}
+		choice := forms.Choice{Value: name}
+		// a label that only repeats the name adds nothing
+		if label := r.text("label"); len(label) > 1 || label.String() != name {
+			choice.Label = label
+		}
+		imp.bundle.Choices[list] = append(imp.bundle.Choices[list], choice)
+		imp.choiceRows[list] = append(imp.choiceRows[list], r)
+	}
+}
+
+func (imp *importer) readSurvey(rows []row) {
+	for _, r := range rows {
+		typ := strings.Join(strings.Fields(r.get("type")), " ")
+		kind, arg, _ := strings.Cut(typ, " ")
+		switch strings.ToLower(typ) {
+		case "begin group", "begin_group":
+			imp.begin(r, false)
+			continue
+		case "begin repeat", "begin_repeat":
+			imp.begin(r, true)
+			continue
+		case "end group", "end_group", "end repeat", "end_repeat":
+			imp.end(r)
+			continue
+		}
+		if typ == "" {
+			imp.report("survey", r.line, "row without a type skipped")
+			continue
+		}
+		if metadataTypes[strings.ToLower(kind)] {
+			imp.report("survey", r.line, "metadata %q is not imported", kind)
+			continue
+		}
+		f, ok := imp.field(r, strings.ToLower(kind), arg)
+		if !ok {
+			continue
+		}
+		imp.addField(f)
+	}
+	if len(imp.stack) > 0 {
+		imp.report("survey", 0, "group %q is never ended", imp.stack[len(imp.stack)-1].name)
+	}
+}
+
+// begin opens a group or repeat. Top-level groups and repeats start a new
+// section; groups inside a repeat only add their relevance to its fields.
+func (imp *importer) begin(r row, repeat bool) {
+	g := group{name: r.get("name"), label: r.text("label"), relevant: imp.expr(r, "relevant", "")}
+	if g.label == nil {
+		g.label = forms.Plain(g.name)
+	}
+	if imp.inRepeat() {
+		if repeat {
+			imp.report("survey", r.line, "repeat %q inside a repeat is imported as a group", g.name)
+		}
+		imp.stack = append(imp.stack, g)
+		return
+	}
+	g.repeat = repeat
+	imp.stack = append(imp.stack, g)
+	imp.current = nil
+
+	if repeat {
+		sec := imp.section()
+		if count := r.get("repeat_count"); count != "" {
+			if n, err := strconv.Atoi(count); err == nil {
+				sec.MinRepeat, sec.MaxRepeat = n, n
+			} else {
+				imp.report("survey", r.line, "repeat_count %q is not a number and is ignored", count)
+			}
+		}
+	}
+}
+
+func (imp *importer) end(r row) {
+	if len(imp.stack) == 0 {
+		imp.report("survey", r.line, "end without a matching begin skipped")
+		return
+	}
+	imp.stack = imp.stack[:len(imp.stack)-1]
+	if !imp.inRepeat() {
+		imp.current = nil // fields after this go into a new section
+	}
+}
+
+func (imp *importer) inRepeat() bool {
+	for _, g := range imp.stack {
+		if g.repeat {
+			return true
+		}
+	}
+	return false
+}
+
+// section returns the section new fields go into, starting one for the open
+// groups if needed.
+func (imp *importer) section() *forms.Section {
+	if imp.current != nil {
+		return imp.current
+	}
+	sec := forms.Section{Title: imp.title, Layout: "stack"}
+	var titles []forms.Text
+	var relevant []string
+	for _, g := range imp.stack {
+		titles = append(titles, g.label)
+		if g.relevant != "" {
+			relevant = append(relevant, g.relevant)
+		}
+		if g.repeat {
+			sec.Repeat = true
+			sec.ID = g.name
+		}
+	}
+	if len(titles) > 0 {
+		sec.Title = forms.JoinText(titles, " / ")
+		sec.ID = imp.stack[len(imp.stack)-1].name
+	}
+	sec.Relevant = joinRelevant(relevant)
+	imp.sections = append(imp.sections, sec)
+	imp.current = &imp.sections[len(imp.sections)-1]
+	return imp.current
+}
+
+func (imp *importer) addField(f forms.Field) {
+	// groups inside a repeat pass their relevance on to each field
+	var relevant []string
+	inRepeat := false
+	for _, g := range imp.stack {
+		if inRepeat && g.relevant != "" {
+			relevant = append(relevant, g.relevant)
+		}
+		inRepeat = inRepeat || g.repeat
+	}
+	if f.Relevant != "" {
+		relevant = append(relevant, f.Relevant)
+	}
+	f.Relevant = joinRelevant(relevant)
+
+	sec := imp.section()
+	sec.Fields = append(sec.Fields, f)
+}
+
+func joinRelevant(exprs []string) string {
+	if len(exprs) < 2 {
+		return strings.Join(exprs, "")
+	}
+	return "(" + strings.Join(exprs, ") && (") + ")"
+}
+
+// field maps one question row onto a field.
+func (imp *importer) field(r row, kind, arg string) (forms.Field, bool) {
+	name := r.get("name")
+	if name == "" {
+		imp.report("survey", r.line, "%s question without a name skipped", kind)
+		return forms.Field{}, false
+	}
+	f := forms.Field{
+		ID:    name,
+		Label: r.text("label"),
+		Hint:  r.text("hint"),
+	}
+	if f.Label == nil {
+		f.Label = forms.Plain(name)
+	}
+	appearance := strings.ToLower(r.get("appearance"))
+
+	switch kind {
+	case "select_one", "select_multiple":
+		f.Type = map[string]string{"select_one": "select", "select_multiple": "select_multiple"}[kind]
+		if _, ok := imp.bundle.Choices[arg]; !ok {
+			imp.report("survey", r.line, "%s refers to missing choice list %q", name, arg)
+		}
+		f.ChoiceList = arg
+		if filter := r.get("choice_filter"); filter != "" {
+			imp.choiceFilter(r, &f, filter)
+		}
+	default:
+		typ, ok := simpleTypes[kind]
+		if !ok {
+			imp.report("survey", r.line, "%s: type %q is not supported; question skipped", name, kind)
+			return forms.Field{}, false
+		}
+		f.Type = typ
+		if mime, ok := mediaTypes[kind]; ok {
+			f.Validation.AllowedTypes = []string{mime}
+		}
+		switch {
+		case kind == "text" && appearance == "multiline":
+			f.Type = "multiline"
+			appearance = ""
+		case kind == "image" && appearance == "signature":
+			f.Type = "signature"
+			f.Validation.AllowedTypes = nil
+			appearance = ""
+		}
+	}
+	if appearance != "" {
+		imp.report("survey", r.line, "%s: appearance %q is ignored", name, appearance)
+	}
+
+	switch req := strings.ToLower(r.get("required")); req {
+	case "", "no", "false", "false()":
+	case "yes", "true", "true()":
+		f.Validation.Required = true
+	default:
+		imp.report("survey", r.line, "%s: conditional required %q is not supported", name, req)
+	}
+	if ro := strings.ToLower(r.get("read_only")); ro == "yes" || ro == "true" || ro == "true()" {
+		if r.get("calculation") == "" {
+			imp.report("survey", r.line, "%s: read_only is only imported with a calculation", name)
+		}
+	}
+
+	f.Relevant = imp.expr(r, "relevant", name)
+	f.Calculate = imp.expr(r, "calculation", name)
+	if def := r.get("default"); def != "" {
+		if strings.Contains(def, "${") || strings.Contains(def, "(") {
+			if converted, err := xpath.ToExpr(def, name); err == nil {
+				f.Default = converted
+			} else {
+				imp.report("survey", r.line, "%s: default %q: %v", name, def, err)
+			}
+		} else {
+			f.Default = def
+		}
+	}
+
+	if constraint := r.get("constraint"); constraint != "" {
+		if pattern, ok := xpath.RegexPattern(constraint); ok {
+			f.Validation.Pattern = pattern
+		} else {
+			f.Validation.Formula = imp.expr(r, "constraint", name)
+		}
+		f.Validation.ErrorMessage = r.text("constraint_message")
+	}
+	return f, true
+}
+
+// expr converts the expression in a column, reporting it if it can't be.
+func (imp *importer) expr(r row, column, self string) string {
+	raw := r.get(column)
+	if raw == "" {
+		return ""
+	}
+	converted, err := xpath.ToExpr(raw, self)
+	if err != nil {
+		imp.report("survey", r.line, "%s %q not imported: %v", column, raw, err)
+		return ""
+	}
+	return converted
+}
+
+// choiceFilter imports the common cascading select filter "column=${parent}",
+// where column is a choices sheet column holding each choice's parent.
+func (imp *importer) choiceFilter(r row, f *forms.Field, filter string) {
+	column, ref, ok := strings.Cut(filter, "=")
+	column, ref = strings.TrimSpace(column), strings.TrimSpace(ref)
+	if !ok || !strings.HasPrefix(ref, "${") || !strings.HasSuffix(ref, "}") || strings.ContainsAny(column, " ()$") {
+		imp.report("survey", r.line, "%s: choice_filter %q is not supported", f.ID, filter)
+		return
+	}
+	parent := strings.TrimSpace(ref[2 : len(ref)-1])
+	f.ChoiceFilter = parent
+
+	list := imp.bundle.Choices[f.ChoiceList]
+	for i, cr := range imp.choiceRows[f.ChoiceList] {
+		if list[i].Parents == nil {
+			list[i].Parents = make(map[string]string)
+		}
+		list[i].Parents[parent] = cr.get(strings.ToLower(column))
+	}
+}
@@ -0,0 +1,280 @@
+// Package xpath translates between the XPath expressions used by XLSForm and
+// ODK XForms and the expressions of form definitions (relevance, calculate,
+// formula), for the subset both can express.
+package xpath
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// functions maps the XPath functions that have a direct equivalent in form
+// expressions.
+var functions = map[string]string{
+	"selected": "selected",
+	"today":    "today",
+	"now":      "now",
+	"not":      "!",
+}
+
+// operators maps XPath's word operators.
+var operators = map[string]string{
+	"and": "&&",
+	"or":  "||",
+	"div": "/",
+	"mod": "%",
+}
+
+// regexCheck matches a pattern check on self, e.g. regex(., '^[0-9]{10}$').
+var regexCheck = regexp.MustCompile(`^\s*regex\(\s*\.\s*,\s*(?:'([^']*)'|"([^"]*)")\s*\)\s*$`)
+
+// RegexPattern returns the pattern of an expression that is only a regex()
+// check on self, which maps onto a validation pattern.
+func RegexPattern(expr string) (string, bool) {
+	m := regexCheck.FindStringSubmatch(expr)
+	if m == nil {
+		return "", false
+	}
+	return m[1] + m[2], true
+}
+
+// ToExpr rewrites an XPath expression such as
+// "/data/age >= 18 and selected(${symptoms}, 'fever')" into form expression
+// syntax: "age >= 18 && selected(symptoms, 'fever')". Fields may be
+// referenced XLSForm style (${name}) or by absolute path, of which only the
+// last step is kept. A lone "." stands for self, the field the expression
+// belongs to. Functions without an equivalent are an error.
+func ToExpr(expr, self string) (string, error) {
+	var out strings.Builder
+	runes := []rune(expr)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return "", fmt.Errorf("unterminated string in %q", expr)
+			}
+			out.WriteString(string(runes[i : end+1]))
+			i = end
+
+		case r == '$' && next == '{':
+			end := i + 2
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end == len(runes) {
+				return "", fmt.Errorf("unterminated reference in %q", expr)
+			}
+			out.WriteString(strings.TrimSpace(string(runes[i+2 : end])))
+			i = end
+
+		case r == '/' && (unicode.IsLetter(next) || next == '_'):
+			end := i
+			for end < len(runes) && (isNameRune(runes[end]) || runes[end] == '/') {
+				end++
+			}
+			path := string(runes[i:end])
+			out.WriteString(path[strings.LastIndex(path, "/")+1:])
+			i = end - 1
+
+		case unicode.IsDigit(r) || (r == '.' && unicode.IsDigit(next)):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			out.WriteString(string(runes[i:end]))
+			i = end - 1
+
+		case r == '.':
+			if next == '.' {
+				return "", fmt.Errorf("relative references (..) are not supported in %q", expr)
+			}
+			if self == "" {
+				return "", fmt.Errorf("%q refers to self (.) outside a field", expr)
+			}
+			out.WriteString(self)
+
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && isNameRune(runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end - 1
+			if op, ok := operators[word]; ok {
+				out.WriteString(op)
+				continue
+			}
+			// the rest must be function calls
+			open := skipSpace(runes, end)
+			if open == len(runes) || runes[open] != '(' {
+				return "", fmt.Errorf("unknown name %q in %q", word, expr)
+			}
+			if word == "true" || word == "false" {
+				// true() and false() are literals
+				closing := skipSpace(runes, open+1)
+				if closing == len(runes) || runes[closing] != ')' {
+					return "", fmt.Errorf("%s() takes no arguments in %q", word, expr)
+				}
+				out.WriteString(word)
+				i = closing
+				continue
+			}
+			fn, ok := functions[word]
+			if !ok {
+				return "", fmt.Errorf("function %s() has no equivalent", word)
+			}
+			out.WriteString(fn)
+
+		case r == '=':
+			out.WriteString("==")
+
+		case (r == '!' || r == '<' || r == '>') && next == '=':
+			out.WriteString(string(r) + "=")
+			i++
+
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String(), nil
+}
+
+// FromExpr rewrites a form expression into XPath, the reverse of ToExpr.
+// ref returns the XPath reference for a field ID, or false if there is no
+// such field.
+func FromExpr(expr string, ref func(id string) (string, bool)) (string, error) {
+	var out strings.Builder
+	// word operators need spaces around them; padded avoids doubling the
+	// space that usually follows in the source
+	padded := false
+	word := func(w string) {
+		s := out.String()
+		if s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "(") {
+			out.WriteByte(' ')
+		}
+		out.WriteString(w + " ")
+		padded = true
+	}
+
+	runes := []rune(expr)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		if unicode.IsSpace(r) && padded {
+			continue
+		}
+		padded = false
+		switch {
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return "", fmt.Errorf("unterminated string in %q", expr)
+			}
+			out.WriteString(string(runes[i : end+1]))
+			i = end
+
+		case unicode.IsDigit(r) || (r == '.' && unicode.IsDigit(next)):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			out.WriteString(string(runes[i:end]))
+			i = end - 1
+
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			name := string(runes[i:end])
+			i = end - 1
+			if open := skipSpace(runes, end); open < len(runes) && runes[open] == '(' {
+				switch name {
+				case "selected", "today", "now":
+					out.WriteString(name)
+				default:
+					return "", fmt.Errorf("function %s() has no XPath equivalent", name)
+				}
+				continue
+			}
+			switch name {
+			case "true", "false":
+				out.WriteString(name + "()")
+			default:
+				path, ok := ref(name)
+				if !ok {
+					return "", fmt.Errorf("unknown field %q in %q", name, expr)
+				}
+				out.WriteString(path)
+			}
+
+		case r == '&' && next == '&':
+			word("and")
+			i++
+		case r == '|' && next == '|':
+			word("or")
+			i++
+		case r == '/':
+			word("div")
+		case r == '%':
+			word("mod")
+		case r == '=' && next == '=':
+			out.WriteString("=")
+			i++
+		case (r == '!' || r == '<' || r == '>') && next == '=':
+			out.WriteString(string(r) + "=")
+			i++
+		case r == '!':
+			// not(...) takes the following parenthesis or field as its argument
+			start := skipSpace(runes, i+1)
+			if start < len(runes) && runes[start] == '(' {
+				out.WriteString("not")
+				i = start - 1
+				continue
+			}
+			end := start
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			path, ok := ref(string(runes[start:end]))
+			if start == end || !ok {
+				return "", fmt.Errorf("cannot translate ! in %q", expr)
+			}
+			out.WriteString("not(" + path + ")")
+			i = end - 1
+		case strings.ContainsRune("+-*(),<> \t\n", r):
+			out.WriteRune(r)
+		default:
+			return "", fmt.Errorf("operator %q has no XPath equivalent in %q", r, expr)
+		}
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-:", r)
+}
+
+func skipSpace(runes []rune, i int) int {
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return i
+}